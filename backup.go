@@ -0,0 +1,364 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultBackupRetention = 20
+
+// backupPolicy snapshots the data file before a store's first write, so each
+// command can be rolled back with `timetrack restore`.
+type backupPolicy struct {
+	dir       string
+	retention int
+	done      bool
+}
+
+type backupInfo struct {
+	Name    string
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// backupStampLayout names snapshots by the time they were taken
+const backupStampLayout = "20060102-150405"
+
+func newBackupPolicy(config Config) *backupPolicy {
+	retention := config.BackupRetention
+	if retention == 0 {
+		retention = defaultBackupRetention
+	}
+	return &backupPolicy{dir: getBackupDir(), retention: retention}
+}
+
+// snapshot runs write (which must create the backup at the given path) once
+// per store, then prunes old snapshots beyond the retention count. A negative
+// retention disables backups entirely.
+func (p *backupPolicy) snapshot(ext string, write func(path string) error) error {
+	if p == nil || p.done || p.retention < 0 {
+		return nil
+	}
+	if err := os.MkdirAll(p.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	stamp := time.Now().Format(backupStampLayout)
+	path := filepath.Join(p.dir, "data-"+stamp+ext)
+	for i := 2; fileExists(path); i++ {
+		path = filepath.Join(p.dir, fmt.Sprintf("data-%s-%d%s", stamp, i, ext))
+	}
+	if err := write(path); err != nil {
+		return fmt.Errorf("failed to back up data: %w", err)
+	}
+	p.done = true
+
+	return p.prune()
+}
+
+func (p *backupPolicy) prune() error {
+	backups, err := listBackups()
+	if err != nil {
+		return err
+	}
+	for i := p.retention; i < len(backups); i++ {
+		if err := os.Remove(backups[i].Path); err != nil {
+			return fmt.Errorf("failed to remove old backup: %w", err)
+		}
+	}
+	return nil
+}
+
+// listBackups returns all snapshots, newest first
+func listBackups() ([]backupInfo, error) {
+	entries, err := os.ReadDir(getBackupDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backups: %w", err)
+	}
+
+	backups := make([]backupInfo, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, "data-") {
+			continue
+		}
+		if ext := filepath.Ext(name); ext != ".json" && ext != ".db" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backupInfo{
+			Name:    name,
+			Path:    filepath.Join(getBackupDir(), name),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+	}
+
+	// Names, not mtimes, give the order: several snapshots can share an
+	// mtime, and snapshot numbers them within the second
+	sort.Slice(backups, func(i, j int) bool {
+		si, ni := backupOrder(backups[i].Name)
+		sj, nj := backupOrder(backups[j].Name)
+		if si != sj {
+			return si > sj
+		}
+		return ni > nj
+	})
+	return backups, nil
+}
+
+// backupOrder splits a snapshot name, data-20251201-093000[-2].json, into
+// its timestamp and its number within that second (1 for the first)
+func backupOrder(name string) (string, int) {
+	stamp := strings.TrimSuffix(strings.TrimPrefix(name, "data-"), filepath.Ext(name))
+	if len(stamp) > len(backupStampLayout) {
+		if n, err := strconv.Atoi(stamp[len(backupStampLayout)+1:]); err == nil {
+			return stamp[:len(backupStampLayout)], n
+		}
+	}
+	return stamp, 1
+}
+
+// findBackup resolves a snapshot by its number in `backup list` or its file name
+func findBackup(ref string) (backupInfo, error) {
+	backups, err := listBackups()
+	if err != nil {
+		return backupInfo{}, err
+	}
+	if n, err := strconv.Atoi(ref); err == nil {
+		if n < 1 || n > len(backups) {
+			return backupInfo{}, fmt.Errorf("no backup #%d (have %d)", n, len(backups))
+		}
+		return backups[n-1], nil
+	}
+	for _, b := range backups {
+		if b.Name == ref || strings.TrimSuffix(b.Name, filepath.Ext(b.Name)) == ref {
+			return b, nil
+		}
+	}
+	return backupInfo{}, fmt.Errorf("backup '%s' not found (see 'timetrack backup list')", ref)
+}
+
+// loadBackup reads a snapshot regardless of which backend wrote it
+func loadBackup(b backupInfo) (map[string]DayData, error) {
+	if filepath.Ext(b.Path) == ".db" {
+		store, err := newSQLiteStore(b.Path, nil)
+		if err != nil {
+			return nil, err
+		}
+		defer store.Close()
+		return store.Range("", "")
+	}
+	return loadData(b.Path)
+}
+
+func printBackupList() error {
+	backups, err := listBackups()
+	if err != nil {
+		return err
+	}
+	if len(backups) == 0 {
+		fmt.Println("No backups yet (one is taken automatically before each change)")
+		return nil
+	}
+
+	fmt.Printf("Backups in %s:\n", getBackupDir())
+	for i, b := range backups {
+		fmt.Printf("  %2d. %s  %s  %s\n", i+1, b.ModTime.Format("02/01/2006 15:04:05"),
+			formatSize(b.Size), b.Name)
+	}
+	return nil
+}
+
+// restoreBackup shows what the snapshot would change and replaces the current
+// data with it once confirmed
+func restoreBackup(store Store, ref string) error {
+	backup, err := findBackup(ref)
+	if err != nil {
+		return err
+	}
+	snapshot, err := loadBackup(backup)
+	if err != nil {
+		return err
+	}
+	current, err := store.Range("", "")
+	if err != nil {
+		return err
+	}
+
+	changes := diffData(current, snapshot)
+	if len(changes) == 0 {
+		fmt.Printf("%s matches current data - nothing to restore\n", backup.Name)
+		return nil
+	}
+
+	fmt.Printf("Restoring %s would change %d day(s):\n", backup.Name, len(changes))
+	for _, line := range changes {
+		fmt.Println("  " + line)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("\nReplace current data with this backup? (y/n): ")
+	input, _ := reader.ReadString('\n')
+	if strings.TrimSpace(strings.ToLower(input)) != "y" {
+		fmt.Println("Restore cancelled")
+		return nil
+	}
+
+	days := make([]DayData, 0, len(snapshot))
+	for _, date := range sortedDates(snapshot) {
+		days = append(days, snapshot[date])
+	}
+	if err := store.ReplaceAll(days); err != nil {
+		return err
+	}
+	fmt.Printf("Restored %d day(s) from %s\n", len(days), backup.Name)
+	return nil
+}
+
+// diffData describes, per date, how to get from before to after
+func diffData(before, after map[string]DayData) []string {
+	dates := make(map[string]bool)
+	for date := range before {
+		dates[date] = true
+	}
+	for date := range after {
+		dates[date] = true
+	}
+	all := make([]string, 0, len(dates))
+	for date := range dates {
+		all = append(all, date)
+	}
+	sort.Strings(all)
+
+	var lines []string
+	for _, date := range all {
+		b, inBefore := before[date]
+		a, inAfter := after[date]
+		switch {
+		case !inBefore:
+			lines = append(lines, fmt.Sprintf("+ %s: %s", date, describeDay(a)))
+		case !inAfter:
+			lines = append(lines, fmt.Sprintf("- %s: %s", date, describeDay(b)))
+		default:
+			if diff := diffDay(b, a); diff != "" {
+				lines = append(lines, fmt.Sprintf("~ %s: %s", date, diff))
+			}
+		}
+	}
+	return lines
+}
+
+func diffDay(before, after DayData) string {
+	var parts []string
+	parts = append(parts, diffDurations(before.Projects, after.Projects, "")...)
+	parts = append(parts, diffDurations(before.ExcludedMeetings, after.ExcludedMeetings, "excluded ")...)
+	for _, name := range sortedKeys(after.Notes) {
+		if after.Notes[name] != before.Notes[name] {
+			parts = append(parts, fmt.Sprintf("%s note %q", name, after.Notes[name]))
+		}
+	}
+	for _, name := range sortedKeys(before.Notes) {
+		if _, ok := after.Notes[name]; !ok {
+			parts = append(parts, fmt.Sprintf("%s note removed", name))
+		}
+	}
+	switch {
+	case before.Leave == after.Leave:
+	case before.Leave == "":
+		parts = append(parts, fmt.Sprintf("marked as %s", after.Leave))
+	case after.Leave == "":
+		parts = append(parts, fmt.Sprintf("%s removed", before.Leave))
+	default:
+		parts = append(parts, fmt.Sprintf("leave %s → %s", before.Leave, after.Leave))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func diffDurations(before, after map[string]int, prefix string) []string {
+	var parts []string
+	for _, name := range sortedKeys(before) {
+		if minutes, ok := after[name]; !ok {
+			parts = append(parts, fmt.Sprintf("%s%s removed", prefix, name))
+		} else if minutes != before[name] {
+			parts = append(parts, fmt.Sprintf("%s%s %s → %s", prefix, name,
+				formatDuration(before[name]), formatDuration(minutes)))
+		}
+	}
+	for _, name := range sortedKeys(after) {
+		if _, ok := before[name]; !ok {
+			parts = append(parts, fmt.Sprintf("%s%s %s added", prefix, name, formatDuration(after[name])))
+		}
+	}
+	return parts
+}
+
+func describeDay(day DayData) string {
+	if len(day.Projects) == 0 {
+		if isNonWorking(day) {
+			return day.Leave
+		}
+		return "no projects"
+	}
+	parts := make([]string, 0, len(day.Projects))
+	for _, name := range sortedKeys(day.Projects) {
+		parts = append(parts, fmt.Sprintf("%s %s", name, formatDuration(day.Projects[name])))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func sortedDates(data map[string]DayData) []string {
+	dates := make([]string, 0, len(data))
+	for date := range data {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+	return dates
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func formatSize(bytes int64) string {
+	if bytes < 1024 {
+		return fmt.Sprintf("%6d B ", bytes)
+	}
+	if bytes < 1024*1024 {
+		return fmt.Sprintf("%6.1f KB", float64(bytes)/1024)
+	}
+	return fmt.Sprintf("%6.1f MB", float64(bytes)/(1024*1024))
+}
@@ -8,7 +8,7 @@ import (
 )
 
 // printCalendar displays a calendar view of tracked time
-func printCalendar(data map[string]DayData, days int) {
+func printCalendar(data map[string]DayData, config Config, days int) {
 	// Get all dates and sort them in reverse chronological order
 	dates := make([]string, 0)
 	for date := range data {
@@ -67,8 +67,8 @@ func printCalendar(data map[string]DayData, days int) {
 	// Print each day
 	for _, date := range dates {
 		day := data[date]
-		available := getAvailablePercent(day)
-		tracked := getTotalTracked(day)
+		available := getAvailablePercent(day, config)
+		tracked := getTrackedPercent(day, config)
 
 		// Format date (e.g., "Mon 08/12/24" - DD/MM/YY UK format)
 		parsedDate, err := time.Parse("2006-01-02", date)
@@ -84,10 +84,10 @@ func printCalendar(data map[string]DayData, days int) {
 
 		// Print each project percentage
 		for _, project := range projects {
-			pct, exists := day.Projects[project]
-			if exists && pct > 0 {
+			minutes, exists := day.Projects[project]
+			if exists && minutes > 0 {
 				// Format: "  " + color + "95.0%" (6 chars) + reset + 9 spaces = 15 visible chars total
-				fmt.Printf("  %s%-6s%s         ", ColorBlue, fmt.Sprintf("%.1f%%", pct), ColorReset)
+				fmt.Printf("  %s%-6s%s         ", ColorBlue, fmt.Sprintf("%.1f%%", toPercent(minutes, config, date)), ColorReset)
 			} else {
 				fmt.Printf("  %-15s", "-")
 			}
@@ -98,8 +98,12 @@ func printCalendar(data map[string]DayData, days int) {
 		totalStr := fmt.Sprintf("%.1f%%", tracked)
 		var colorCode string
 		var statusIcon string
+		dayOff := !isWorkingDay(day, config)
 
-		if tracked > available {
+		if dayOff && tracked == 0 {
+			colorCode = ColorGray // Nothing scheduled
+			statusIcon = ""
+		} else if tracked > available {
 			colorCode = ColorRed // Over-allocated
 			statusIcon = " ⚠️"
 		} else if remaining < 10 {
@@ -114,7 +118,11 @@ func printCalendar(data map[string]DayData, days int) {
 
 		// Print available percentage aligned to the right (6 chars to fit "100.0%")
 		availStr := fmt.Sprintf("%.1f%%", available)
-		if day.ExcludedPercent > 0 {
+		if isNonWorking(day) {
+			fmt.Printf("  %s%6s%s  %s", ColorGray, "leave", ColorReset, day.Leave)
+		} else if dayOff {
+			fmt.Printf("  %s%6s%s", ColorGray, "off", ColorReset)
+		} else if len(day.ExcludedMeetings) > 0 {
 			fmt.Printf("  %s%6s%s", ColorGray, availStr, ColorReset)
 		} else {
 			fmt.Printf("  %6s", availStr)
@@ -130,13 +138,14 @@ func printCalendar(data map[string]DayData, days int) {
 	fmt.Printf("%s●%s On track  ", ColorGreen, ColorReset)
 	fmt.Printf("%s●%s Nearly full (<10%% left)  ", ColorYellow, ColorReset)
 	fmt.Printf("%s●%s Over-allocated\n", ColorRed, ColorReset)
-	fmt.Printf("  Tracked = time logged  |  Avail = available after excluding meetings ")
+	fmt.Printf("  Tracked = share of the day's scheduled hours  |  Avail = available after excluding meetings ")
 	fmt.Printf("(%sgray%s = has exclusions)\n", ColorGray, ColorReset)
+	fmt.Printf("  leave = holiday or leave day  |  off = no hours scheduled\n")
 	fmt.Println()
 }
 
 // printCompactCalendar shows a more condensed calendar view
-func printCompactCalendar(data map[string]DayData, days int) {
+func printCompactCalendar(data map[string]DayData, config Config, days int) {
 	// Get all dates and sort them in reverse chronological order
 	dates := make([]string, 0)
 	for date := range data {
@@ -160,8 +169,8 @@ func printCompactCalendar(data map[string]DayData, days int) {
 
 	for _, date := range dates {
 		day := data[date]
-		available := getAvailablePercent(day)
-		tracked := getTotalTracked(day)
+		available := getAvailablePercent(day, config)
+		tracked := getTrackedPercent(day, config)
 		remaining := available - tracked
 
 		// Format date
@@ -175,7 +184,11 @@ func printCompactCalendar(data map[string]DayData, days int) {
 
 		// Status icon
 		statusIcon := "⏳"
-		if tracked > available {
+		if isNonWorking(day) {
+			statusIcon = "🌴"
+		} else if getDayMinutes(config, date) == 0 && tracked == 0 {
+			statusIcon = "–"
+		} else if tracked > available {
 			statusIcon = "⚠️"
 		} else if remaining < 1 {
 			statusIcon = "✓"
@@ -185,7 +198,12 @@ func printCompactCalendar(data map[string]DayData, days int) {
 
 		// Progress bar for the day
 		barWidth := 30
-		filledWidth := int((tracked / available) * float64(barWidth))
+		filledWidth := 0
+		if available > 0 {
+			filledWidth = int((tracked / available) * float64(barWidth))
+		} else if tracked > 0 {
+			filledWidth = barWidth
+		}
 		if filledWidth > barWidth {
 			filledWidth = barWidth
 		}
@@ -206,13 +224,16 @@ func printCompactCalendar(data map[string]DayData, days int) {
 		}
 		bar += "]"
 		fmt.Printf("%s  %.1f%%", bar, tracked)
+		if isNonWorking(day) {
+			fmt.Printf("  %s(%s)%s", ColorGray, day.Leave, ColorReset)
+		}
 
 		// Projects list
 		if len(day.Projects) > 0 {
 			fmt.Println()
 			projects := sortedKeys(day.Projects)
 			for _, name := range projects {
-				pct := day.Projects[name]
+				pct := toPercent(day.Projects[name], config, date)
 				fmt.Printf("    • %s%-5.1f%%%s  %s\n", ColorBlue, pct, ColorReset, name)
 			}
 		} else {
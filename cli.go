@@ -0,0 +1,413 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Command is a timetrack subcommand. Each one declares its arguments, flags
+// and help text here; main parses the global flags and dispatches to Run.
+type Command struct {
+	Name    string
+	Aliases []string
+	Args    string // Arguments shown in usage, e.g. "<project> <hours>"
+	Summary string
+	Group   string     // Help section
+	More    []helpLine // Further usage lines, e.g. subcommands
+	Flags   []string   // Command-specific flags, for completion
+	MinArgs int        // Fewer positional arguments prints usage
+
+	Date bool // Honours --date
+	JSON bool // Honours --json
+
+	NoData     bool     // Doesn't load the config or tracked data
+	NoLock     bool     // Runs without taking the data lock (implies NoData)
+	NoLockArgs []string // Subcommands that run like NoLock, e.g. "edit" for config edit
+	RawArgs    bool     // Gets its arguments as typed, global flags and all (implies NoLock)
+	Hidden     bool     // Left out of help and completion
+
+	Run func(ctx *Context, args []string) error
+}
+
+type helpLine struct {
+	Usage   string // After "timetrack "
+	Summary string
+}
+
+// Context is what a command runs with: the global flags and, unless the
+// command is NoData, the config and store
+type Context struct {
+	Command string // Name as typed
+	Config  Config
+	Store   *journalStore // Journals everything the command writes
+	Base    Store         // The store underneath, for undo and redo
+	Today   DayData       // Today, with recurring meetings applied
+
+	Date    string // --date, or today
+	DateSet bool   // --date was given
+	JSON    bool
+	Quiet   bool
+}
+
+// globalFlags apply to every command, wherever they appear
+var globalFlags = []helpLine{
+	{"--date <date>, -d <date>", "Work with another day, for commands that take one"},
+	{"--json", "Print machine-readable JSON (status, show, search, notes, timer, projects list)"},
+	{"--quiet, -q", "Don't show the day's status after a change"},
+	{"--data-dir <dir>", "Keep data and config in <dir> instead of ~/.timetrack"},
+}
+
+// errUsage makes the dispatcher print the command's usage
+var errUsage = errors.New("usage")
+
+// parsedFlags holds the global flags found on the command line
+type parsedFlags struct {
+	Date    string
+	DateSet bool
+	JSON    bool
+	Quiet   bool
+	DataDir string
+}
+
+// parseGlobalFlags pulls the global flags out of args, leaving the command
+// and its own arguments. A note after -m is never taken for a flag.
+func parseGlobalFlags(args []string) (parsedFlags, []string, error) {
+	var flags parsedFlags
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch arg {
+		case "--json":
+			flags.JSON = true
+		case "--quiet", "-q":
+			flags.Quiet = true
+		case "--data-dir":
+			if i+1 >= len(args) {
+				return flags, nil, fmt.Errorf("--data-dir flag requires a directory")
+			}
+			flags.DataDir = args[i+1]
+			i++
+		case "--date", "-d":
+			if i+1 >= len(args) {
+				return flags, nil, fmt.Errorf("--date flag requires a value")
+			}
+			rest = append(rest, arg, args[i+1])
+			i++
+		case "-m", "--message":
+			rest = append(rest, arg)
+			if i+1 < len(args) {
+				rest = append(rest, args[i+1])
+				i++
+			}
+		default:
+			rest = append(rest, arg)
+		}
+	}
+
+	// --date is read the same way for every command
+	date, remaining, err := getTargetDate(rest, "")
+	if err != nil {
+		return flags, nil, err
+	}
+	flags.Date = date
+	flags.DateSet = len(remaining) != len(rest)
+	return flags, remaining, nil
+}
+
+// findCommand looks a command up by name or alias
+func findCommand(name string) *Command {
+	name = strings.ToLower(name)
+	for _, cmd := range commands {
+		if cmd.Name == name {
+			return cmd
+		}
+		for _, alias := range cmd.Aliases {
+			if alias == name {
+				return cmd
+			}
+		}
+	}
+	return nil
+}
+
+// commandNames lists the visible commands, for completion
+func commandNames() []string {
+	var names []string
+	for _, cmd := range commands {
+		if !cmd.Hidden {
+			names = append(names, cmd.Name)
+		}
+	}
+	return names
+}
+
+func (c *Command) usage() string {
+	if c.Args == "" {
+		return c.Name
+	}
+	return c.Name + " " + c.Args
+}
+
+func (c *Command) printUsage() {
+	fmt.Println("Usage: timetrack " + c.usage())
+	for _, line := range c.More {
+		fmt.Println("       timetrack " + line.Usage)
+	}
+}
+
+// runCommand parses the command line and runs the command it names. With no
+// command it shows today's status. It returns the exit code: 1 when the
+// command couldn't run or failed.
+func runCommand(argv []string) (code int) {
+	// Completion gets the words being completed untouched: a --date in
+	// them belongs to the command line being typed, not to this one
+	if len(argv) > 0 {
+		if cmd := findCommand(argv[0]); cmd != nil && cmd.RawArgs {
+			args := argv[1:]
+			if len(args) < cmd.MinArgs {
+				cmd.printUsage()
+				return 1
+			}
+			ctx := &Context{Command: strings.ToLower(argv[0]), Date: today()}
+			return ctx.report(cmd, cmd.Run(ctx, args))
+		}
+	}
+
+	flags, args, err := parseGlobalFlags(argv)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	if flags.DataDir != "" {
+		dataDirOverride = flags.DataDir
+	}
+
+	cmd := statusCommand
+	name := ""
+	if len(args) > 0 {
+		name = strings.ToLower(args[0])
+		args = args[1:]
+		if cmd = findCommand(name); cmd == nil {
+			fmt.Printf("Unknown command: %s\n", name)
+			printHelp()
+			return 1
+		}
+	}
+	if flags.DateSet && !cmd.Date {
+		fmt.Printf("Error: --date isn't supported by '%s'\n", cmd.Name)
+		return 1
+	}
+	if flags.JSON && !cmd.JSON {
+		fmt.Printf("Error: --json isn't supported by '%s'\n", cmd.Name)
+		return 1
+	}
+	if len(args) < cmd.MinArgs {
+		cmd.printUsage()
+		return 1
+	}
+
+	ctx := &Context{Command: name, Date: flags.Date, DateSet: flags.DateSet, JSON: flags.JSON, Quiet: flags.Quiet}
+	if cmd.NoLock || cmd.RawArgs || (len(args) > 0 && containsName(cmd.NoLockArgs, args[0])) {
+		return ctx.report(cmd, cmd.Run(ctx, args))
+	}
+
+	lock, err := acquireLock(true, commandLockTimeout)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	defer lock.Release()
+
+	if cmd.NoData {
+		return ctx.report(cmd, cmd.Run(ctx, args))
+	}
+	if err := ctx.load(); err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	defer ctx.Base.Close()
+
+	// Everything this command writes is journaled as one undoable operation
+	defer func() {
+		if err := ctx.Store.Commit(commandLine(argv)); err != nil {
+			fmt.Println("Error: failed to record operation:", err)
+			code = 1
+		}
+	}()
+	return ctx.report(cmd, cmd.Run(ctx, args))
+}
+
+// load opens the config and store and makes sure today exists
+func (ctx *Context) load() error {
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	base, err := openStore(config)
+	if err != nil {
+		return err
+	}
+
+	day, exists, err := base.LoadDay(today())
+	if err != nil {
+		base.Close()
+		return err
+	}
+	// Save day data if it's new (has recurring meetings applied)
+	if !exists {
+		day = newDay(config, today())
+		if len(day.ExcludedMeetings) > 0 {
+			if err := base.SaveDay(day); err != nil {
+				base.Close()
+				return err
+			}
+		}
+	}
+
+	ctx.Config = config
+	ctx.Base = base
+	ctx.Store = newJournalStore(base)
+	ctx.Today = day
+	return nil
+}
+
+// withData runs fn with the data lock held and the store loaded, for
+// commands such as interactive that wait on the user between changes and so
+// can't hold the lock throughout. What fn writes is journaled as one op.
+func withData(command string, fn func(ctx *Context) error) error {
+	lock, err := acquireLock(true, commandLockTimeout)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	ctx := &Context{Command: command, Date: today()}
+	if err := ctx.load(); err != nil {
+		return err
+	}
+	defer ctx.Base.Close()
+
+	err = fn(ctx)
+	if commitErr := ctx.Store.Commit(command); commitErr != nil {
+		return fmt.Errorf("failed to record operation: %w", commitErr)
+	}
+	return err
+}
+
+// report prints a command's error, if any, and returns the exit code for it
+func (ctx *Context) report(cmd *Command, err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, errUsage):
+		cmd.printUsage()
+	default:
+		fmt.Println("Error:", err)
+	}
+	return 1
+}
+
+// status shows a day after a change, unless --quiet
+func (ctx *Context) status(day DayData) {
+	if !ctx.Quiet {
+		printStatus(day, ctx.Config)
+	}
+}
+
+// onDate prints " on <date>" (or another preposition) when the command is
+// working on a day other than today
+func (ctx *Context) onDate(preposition string) {
+	if ctx.Date != today() {
+		fmt.Printf(" %s %s", preposition, ctx.Date)
+	}
+}
+
+// printJSON writes v as indented JSON for --json
+func printJSON(v any) error {
+	bytes, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	fmt.Println(string(bytes))
+	return nil
+}
+
+// helpGroups is the order of the sections in help
+var helpGroups = []string{
+	"Tracking", "Live Timer", "Viewing", "Holidays & Leave", "Export & Import", "Backups",
+	"Projects & Aliases", "Config", "Shell Completion", "Reminders",
+}
+
+func printHelpLine(usage, summary string) {
+	const width = 32
+	if len(usage) > width {
+		fmt.Printf("  %s\n  %-*s %s\n", usage, width, "", summary)
+		return
+	}
+	fmt.Printf("  %-*s %s\n", width, usage, summary)
+}
+
+// printHelp lists every command, built from the registry
+func printHelp() {
+	fmt.Println()
+	fmt.Println("timetrack - Track your day in hours")
+	fmt.Println()
+	fmt.Println("Usage:")
+	printHelpLine("timetrack", "Show today's status")
+
+	for _, group := range helpGroups {
+		fmt.Printf("\n%s:\n", group)
+		for _, cmd := range commands {
+			if cmd.Group != group || cmd.Hidden {
+				continue
+			}
+			if cmd.Summary != "" {
+				printHelpLine("timetrack "+cmd.usage(), cmd.Summary)
+			}
+			for _, line := range cmd.More {
+				printHelpLine("timetrack "+line.Usage, line.Summary)
+			}
+		}
+	}
+
+	fmt.Println("\nGlobal Flags:")
+	for _, flag := range globalFlags {
+		printHelpLine(flag.Usage, flag.Summary)
+	}
+
+	fmt.Print(`
+Note Flag (for add, fill, edit):
+  -m <note> or --message <note>  Say what the time was spent on
+    timetrack add bugs 2 -m "fixed login crash"
+
+Dates (for --date and commands that take a date):
+    DD-MM-YYYY (preferred): 05-12-2024
+    DD/MM/YYYY:             05/12/2024
+    YYYY-MM-DD (ISO):       2024-12-05
+    today, yesterday
+
+  Examples:
+    timetrack add bugs 2 --date 05-12-2024
+    timetrack fill "Main Project" -d 2024-12-05
+    timetrack copy 08-12-2024 -d 10-12-2024  (copy to a specific date)
+
+Ranges (for show, export, report and search):
+    --from <date> [--to <date>]  Any span of days (--to alone: everything up to it)
+    --week 2025-W49              An ISO week, Monday to Sunday
+    --month 2025-12              A calendar month
+    this-week, last-week, this-month, last-month
+    A range without tracked days shows and exports nothing.
+
+  Examples:
+    timetrack export csv last-week
+    timetrack show --month 2025-12
+    timetrack report week --week 2025-W49
+
+Days: mon, tue, wed, thu, fri, sat, sun, daily, weekdays
+
+Note: All input is in hours and stored as minutes. Percentages are shown against
+each day's scheduled hours (config schedule, else day_hours, default 8).
+`)
+}
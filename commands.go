@@ -0,0 +1,1342 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// commands is the registry of every timetrack command, in help order within
+// each group. It's filled in by init because printHelp reads it.
+var commands []*Command
+
+// statusCommand runs when no command is given
+var statusCommand *Command
+
+func init() {
+	commands = []*Command{
+		{Name: "interactive", Aliases: []string{"i"}, Summary: "Interactive menu mode", Group: "Tracking",
+			NoLock: true, Run: runInteractiveCommand},
+		{Name: "add", Args: "<project> <hours>", Summary: "Add/update time to a project", Group: "Tracking",
+			More:  []helpLine{{"add <project> 09:15-11:45", "Add a time range (several: 09:00-10:00,14:00-15:30)"}},
+			Flags: []string{"-m", "--new"}, MinArgs: 2, Date: true, Run: runAdd},
+		{Name: "fill", Args: "<project>", Summary: "Fill remaining time with project", Group: "Tracking",
+			Flags: []string{"-m", "--new"}, MinArgs: 1, Date: true, Run: runFill},
+		{Name: "edit", Args: "<project> <hours>", Summary: "Update existing project time", Group: "Tracking",
+			Flags: []string{"-m"}, MinArgs: 2, Date: true, Run: runEdit},
+		{Name: "copy", Args: "<source-date>", Summary: "Copy projects from another date (to today or --date)", Group: "Tracking",
+			MinArgs: 1, Date: true, Run: runCopy},
+		{Name: "exclude", Aliases: []string{"ex"}, Args: "<name> <hours>", Summary: "Exclude ceremony time (one-off)", Group: "Tracking",
+			MinArgs: 2, Date: true, Run: runExclude},
+		{Name: "rm", Aliases: []string{"remove"}, Args: "<project>", Summary: "Remove a project entry", Group: "Tracking",
+			MinArgs: 1, Date: true, Run: runRemove},
+		{Name: "rmex", Args: "<name>", Summary: "Remove an excluded meeting", Group: "Tracking",
+			MinArgs: 1, Date: true, Run: runRemoveExcluded},
+		{Name: "undo", Args: "[n]", Summary: "Undo the last n changes (default 1)", Group: "Tracking",
+			More: []helpLine{{"undo [n] --date <date>", "Undo the last n changes to that day only"}},
+			Date: true, Run: runUndo},
+		{Name: "redo", Args: "[n]", Summary: "Redo the last n undone changes", Group: "Tracking",
+			Run: runUndo},
+		{Name: "log", Args: "[n]", Summary: "List recent changes (default 10)", Group: "Tracking",
+			Run: runLog},
+		{Name: "clear", Summary: "Clear today's data (or --date's)", Group: "Tracking",
+			Date: true, Run: runClear},
+
+		{Name: "start-timer", Args: "<project>", Summary: "Start a live timer for a project", Group: "Live Timer",
+			Flags: []string{"--new"}, MinArgs: 1, Run: runStartTimer},
+		{Name: "switch", Args: "<project>", Summary: "Stop the running timer and start another", Group: "Live Timer",
+			Flags: []string{"--new"}, MinArgs: 1, Run: runSwitchTimer},
+		{Name: "stop-timer", Summary: "Stop the timer and add the elapsed time", Group: "Live Timer",
+			Run: runStopTimer},
+		{Name: "pause", Summary: "Pause the running timer", Group: "Live Timer",
+			Run: func(ctx *Context, args []string) error { return pauseTimer() }},
+		{Name: "resume", Summary: "Resume the paused timer", Group: "Live Timer",
+			Run: func(ctx *Context, args []string) error { return resumeTimer() }},
+		{Name: "timer", Summary: "Show the running timer", Group: "Live Timer",
+			JSON: true, Run: runTimer},
+
+		{Name: "summary", Aliases: []string{"sum"}, Summary: "Show the day's status", Group: "Viewing",
+			Date: true, JSON: true, Run: runStatus},
+		{Name: "show", Aliases: []string{"cal", "calendar", "history", "hist"}, Args: "[days]", Summary: "Calendar view (default: 7 days)", Group: "Viewing",
+			More:  []helpLine{{"show <range>", "Calendar view of every day in a range (see Ranges below)"}},
+			Flags: rangeFlags, JSON: true, Run: runShow},
+		{Name: "notes", Args: "[text]", Summary: "List entry notes (only those containing text)", Group: "Viewing",
+			JSON: true, Run: runNotes},
+		{Name: "search", Aliases: []string{"find"}, Args: "<query>", Summary: "Find days by project, meeting or note text", Group: "Viewing",
+			More:  []helpLine{{"search <query> [range] [--project <name>] [--min <hours>]", "Narrow the search (see Ranges below)"}},
+			Flags: append([]string{"--project", "--min"}, rangeFlags...), JSON: true, Run: runSearchCommand},
+		{Name: "report", Args: "week|project <name>|stats", Summary: "Older text reports (prefer show and export)", Group: "Viewing",
+			More: []helpLine{
+				{"report week|stats --by <category|client|tag|billable>", "Subtotal time by project metadata"},
+				{"report week|project <name>|stats <range>", "Report on a range instead (see Ranges below)"},
+			},
+			Flags: append([]string{"--by"}, rangeFlags...), Run: runReport},
+
+		{Name: "leave", Aliases: []string{"holiday", "holidays"}, Args: "add|rm|list|import", Group: "Holidays & Leave",
+			More: []helpLine{
+				{"leave add <from> <to> [type]", "Mark days as leave (type defaults to \"leave\")"},
+				{"leave rm <from> [to]", "Unmark leave days"},
+				{"leave list", "List holidays and leave"},
+				{"leave import <file.ics> [type]", "Mark holidays from an iCalendar file"},
+			},
+			Run: func(ctx *Context, args []string) error { return handleLeave(ctx.Store, ctx.Config, args) }},
+
+		{Name: "export", Args: "csv|all|json|xlsx [file]", Group: "Export & Import",
+			More: []helpLine{
+				{"export csv [file]", "Export week to CSV (auto-discovered projects)"},
+				{"export all [file]", "Export all data to CSV"},
+				{"export json [file]", "Export as JSON"},
+				{"export xlsx [file] [--sheets week|month]", "Export an Excel workbook, a sheet per week or month"},
+				{"export csv|all|json|xlsx <range> [file]", "Export a range instead (see Ranges below)"},
+				{"export csv|all --by <category|client|tag|billable>", "Group project columns with subtotals"},
+			},
+			Flags: append([]string{"--by", "--sheets"}, rangeFlags...), Run: runExport},
+		{Name: "import", Args: "<file.csv|file.xlsx>", Summary: "Import data from CSV or an Excel workbook", Group: "Export & Import",
+			More: []helpLine{
+				{"import ics <file.ics> [range] [--dry-run]", "Book calendar events as meetings or project time (default: this week)"},
+			},
+			Flags: append([]string{"--dry-run"}, rangeFlags...), MinArgs: 1, Run: runImport},
+
+		{Name: "backup", Aliases: []string{"backups"}, Args: "list", Summary: "List automatic snapshots (taken before each change)", Group: "Backups",
+			Run: runBackup},
+		{Name: "restore", Args: "<snapshot>", Summary: "Show differences and restore a snapshot (number or name)", Group: "Backups",
+			MinArgs: 1, Run: runRestore},
+
+		{Name: "projects", Args: "list|meta|rename|merge|archive|unarchive", Group: "Projects & Aliases",
+			More: []helpLine{
+				{"projects list", "List all projects (auto-discovered from time)"},
+				{"projects meta <project> [category=..] [client=..] [billable=yes|no] [tags=a,b]", "Show or set a project's category, client, billing and tags"},
+				{"projects rename <old> <new>", "Rename a project throughout history"},
+				{"projects merge <from> <into>", "Fold one project's time into another"},
+				{"projects archive [project]", "Hide a finished project (no args: list archived)"},
+				{"projects unarchive <project>", "Bring an archived project back"},
+				{"projects set \"P1,P2\"", "Manually configure project list (rarely needed)"},
+				{"projects parse \"...\"", "Parse Excel header for projects (rarely needed)"},
+			},
+			MinArgs: 1, JSON: true, Run: runProjects},
+		{Name: "alias", Args: "<short> <full>", Summary: "Create/update alias", Group: "Projects & Aliases",
+			More: []helpLine{
+				{"alias rm <short>", "Remove alias"},
+				{"alias list", "List all aliases"},
+			},
+			MinArgs: 1, Run: runAlias},
+
+		{Name: "config", Summary: "Show current config", Group: "Config",
+			More: []helpLine{
+				{"config edit", "Open config file in editor"},
+				{"config day-hours <h>", "Set working day length (default 8)"},
+				{"config strict <on|off>", "Only accept known projects; create new ones with --new"},
+				{"config csv-columns <order>|reset", "CSV export column order, e.g. \"date,total,projects,notes\""},
+				{"config timesheet", "Show the timesheet template CSV exports follow"},
+				{"config timesheet columns <P1,P2,..>|projects", "Project columns in sheet order (projects: the config list)"},
+				{"config timesheet date-format <fmt>", "Date cells, e.g. dd/mm/yyyy, d-mmm, ddd dd/mm"},
+				{"config timesheet values <percent|hours|decimal>", "Value cells as % of the day, hours or a fraction of the day"},
+				{"config timesheet empty <blank|0>", "What empty cells hold"},
+				{"config timesheet total <value|sum|formula>", "Total cell: the value, =SUM(...) or e.g. \"=SUM(B{row}:F{row})\""},
+				{"config timesheet off", "Go back to the default export format"},
+				{"config schedule", "Show hours per weekday and date overrides"},
+				{"config schedule <days|date> <h>", "Set hours, e.g. \"fri 4\", \"weekends 0\", \"24-12-2025 4\""},
+				{"config schedule clear <days|date>", "Go back to the default for those days"},
+			},
+			NoLockArgs: []string{"edit"}, Run: runConfig},
+		{Name: "meeting", Args: "add|rm|rule|rules", Group: "Config",
+			More: []helpLine{
+				{"meeting add <name> <hours> <days>", "Add recurring meeting"},
+				{"meeting rm <name>", "Remove recurring meeting"},
+				{"meeting rule <pattern> exclude [name]", "Import calendar events with the pattern in their title as excluded meetings"},
+				{"meeting rule <pattern> project <project>", "Import them as project time instead"},
+				{"meeting rule <pattern> skip", "Leave them out of calendar imports"},
+				{"meeting rule rm <pattern>", "Remove a calendar rule"},
+				{"meeting rules", "List calendar rules"},
+			},
+			MinArgs: 1, Run: runMeeting},
+		{Name: "reminder", Args: "<times>", Summary: "Set reminder times (e.g., \"09:00,12:00,15:00\")", Group: "Config",
+			Run: runReminder},
+		{Name: "url", Summary: "Show current timesheet URL", Group: "Config",
+			More: []helpLine{
+				{"url set <url>", "Set online timesheet URL"},
+				{"url open", "Open timesheet URL in browser"},
+				{"url rm", "Clear timesheet URL"},
+			},
+			Run: runURL},
+		{Name: "storage", Args: "<json|sqlite>", Summary: "Switch storage backend (copies existing data)", Group: "Config",
+			Run: runStorage},
+		{Name: "migrate", Args: "[--dry-run]", Summary: "Upgrade data/config files to the current format", Group: "Config",
+			NoData: true, Run: runMigrateCommand},
+
+		{Name: "completion", Args: "<bash|zsh|fish>", Summary: "Print a completion script (commands, projects, aliases, dates)", Group: "Shell Completion",
+			MinArgs: 1, RawArgs: true, Run: func(ctx *Context, args []string) error { return printCompletionScript(strings.ToLower(args[0])) }},
+		{Name: "__complete", Hidden: true, RawArgs: true,
+			Run: func(ctx *Context, args []string) error { runComplete(args); return nil }},
+
+		{Name: "start", Summary: "Start reminder service (foreground)", Group: "Reminders",
+			NoLock: true, Run: runStartDaemon},
+		{Name: "start-bg", Summary: "Start reminder service (background)", Group: "Reminders",
+			NoLock: true, Run: runStartDaemonBackground},
+		{Name: "stop", Summary: "Stop reminder service", Group: "Reminders",
+			NoLock: true, Run: func(ctx *Context, args []string) error { stopDaemon(); return nil }},
+		{Name: "status", Summary: "Check if reminder service is running", Group: "Reminders",
+			NoLock: true, Run: runDaemonStatus},
+		{Name: "test-notify", Summary: "Send a test notification", Group: "Reminders",
+			NoLock: true, Run: func(ctx *Context, args []string) error {
+				sendNotification("⏰ TimeTrack Test", "Notifications are working!")
+				fmt.Println("Test notification sent")
+				return nil
+			}},
+
+		{Name: "help", Aliases: []string{"-h", "--help"}, Hidden: true, NoLock: true,
+			Run: func(ctx *Context, args []string) error { printHelp(); return nil }},
+	}
+	statusCommand = findCommand("summary")
+}
+
+// dayJSON is a day with its totals, for --json
+type dayJSON struct {
+	DayData
+	AvailableMinutes int `json:"available_minutes"`
+	TrackedMinutes   int `json:"tracked_minutes"`
+}
+
+func newDayJSON(day DayData, config Config) dayJSON {
+	return dayJSON{DayData: day, AvailableMinutes: getAvailableMinutes(day, config), TrackedMinutes: getTotalTracked(day)}
+}
+
+func runStatus(ctx *Context, args []string) error {
+	day := ctx.Today
+	if ctx.Date != today() {
+		var err error
+		if day, err = getDateData(ctx.Store, ctx.Config, ctx.Date); err != nil {
+			return err
+		}
+	}
+	if ctx.JSON {
+		return printJSON(newDayJSON(day, ctx.Config))
+	}
+	printStatus(day, ctx.Config)
+	if ctx.Command == "" {
+		if running, _ := loadTimer(); running != nil {
+			printTimer(ctx.Config)
+		}
+	}
+	return nil
+}
+
+// runInteractiveCommand takes the data lock for each menu action rather
+// than for the whole session, so other commands and reminders aren't kept
+// waiting while a menu is open
+func runInteractiveCommand(ctx *Context, args []string) error {
+	runInteractive()
+	return nil
+}
+
+func runAdd(ctx *Context, args []string) error {
+	config, store := ctx.Config, ctx.Store
+	note, args, err := getNoteFlag(args)
+	if err != nil {
+		return err
+	}
+	allowNew, args := getNewFlag(args)
+	if len(args) < 2 {
+		return errUsage
+	}
+	project, err := resolveProjectInput(args[0], store, config, allowNew)
+	if err != nil {
+		return err
+	}
+
+	// Time ranges are added to the project alongside any already recorded
+	if isIntervalSpec(args[1]) {
+		intervals, err := parseIntervals(args[1])
+		if err != nil {
+			return err
+		}
+		targetDay, err := addProjectIntervals(store, config, ctx.Date, project, intervals, note)
+		if err != nil {
+			return err
+		}
+		minutes := 0
+		for _, iv := range intervals {
+			minutes += iv.minutes()
+		}
+		fmt.Printf("Added %s (%s) to %s", args[1], formatDuration(minutes), project)
+		ctx.onDate("on")
+		fmt.Println()
+		warnOverAllocated(targetDay, config)
+		ctx.status(targetDay)
+		return nil
+	}
+
+	hours, err := strconv.ParseFloat(args[1], 64)
+	if err != nil {
+		return fmt.Errorf("invalid hours: %s", args[1])
+	}
+
+	// Validate time
+	minutes := hoursToMinutes(hours)
+	pct := toPercent(minutes, config, ctx.Date)
+	if pct > 100 {
+		fmt.Printf("⚠️  Warning: %.2f hours is %.1f%% of the %s working day (>100%%). Did you mean %.2f hours?\n",
+			hours, pct, formatDuration(percentBase(config, ctx.Date)), hours/10)
+	}
+
+	targetDay, err := setProjectTime(store, config, ctx.Date, project, minutes, note)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Added %.1f%% to %s", pct, project)
+	ctx.onDate("on")
+	fmt.Println()
+	warnOverAllocated(targetDay, config)
+	ctx.status(targetDay)
+	return nil
+}
+
+func runFill(ctx *Context, args []string) error {
+	config, store := ctx.Config, ctx.Store
+	note, args, err := getNoteFlag(args)
+	if err != nil {
+		return err
+	}
+	allowNew, args := getNewFlag(args)
+	if len(args) < 1 {
+		return errUsage
+	}
+	project, err := resolveProjectInput(args[0], store, config, allowNew)
+	if err != nil {
+		return err
+	}
+
+	// Get data for target date
+	targetDay, err := getDateData(store, config, ctx.Date)
+	if err != nil {
+		return err
+	}
+
+	available := getAvailableMinutes(targetDay, config)
+	tracked := getTotalTracked(targetDay)
+	remaining := available - tracked
+
+	if remaining <= 0 {
+		fmt.Printf("⚠️  No remaining time to fill (%.1f%% available, %.1f%% already tracked)",
+			toPercent(available, config, ctx.Date), toPercent(tracked, config, ctx.Date))
+		ctx.onDate("on")
+		fmt.Println()
+		return nil
+	}
+
+	setProjectMinutes(&targetDay, project, remaining)
+	setProjectNote(&targetDay, project, note, false)
+	if err := store.SaveDay(targetDay); err != nil {
+		return err
+	}
+
+	fmt.Printf("Filled remaining %.1f%% (%.2f hours) to %s", toPercent(remaining, config, ctx.Date), float64(remaining)/60.0, project)
+	ctx.onDate("on")
+	fmt.Println()
+	ctx.status(targetDay)
+	return nil
+}
+
+func runEdit(ctx *Context, args []string) error {
+	config, store := ctx.Config, ctx.Store
+	note, args, err := getNoteFlag(args)
+	if err != nil {
+		return err
+	}
+	if len(args) < 2 {
+		return errUsage
+	}
+	project, err := resolveProjectInput(args[0], store, config, false)
+	if err != nil {
+		return err
+	}
+	hours, err := strconv.ParseFloat(args[1], 64)
+	if err != nil {
+		return fmt.Errorf("invalid hours: %s", args[1])
+	}
+
+	// Get data for target date
+	targetDay, err := getDateData(store, config, ctx.Date)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := targetDay.Projects[project]; !ok {
+		fmt.Printf("Project '%s' not found in tracking", project)
+		ctx.onDate("for")
+		fmt.Println()
+		fmt.Println("Use 'add' to create a new entry")
+		return nil
+	}
+
+	minutes := hoursToMinutes(hours)
+	setProjectMinutes(&targetDay, project, minutes)
+	setProjectNote(&targetDay, project, note, false)
+	if err := store.SaveDay(targetDay); err != nil {
+		return err
+	}
+	fmt.Printf("Updated %s to %.1f%%", project, toPercent(minutes, config, ctx.Date))
+	ctx.onDate("on")
+	fmt.Println()
+	ctx.status(targetDay)
+	return nil
+}
+
+func runCopy(ctx *Context, args []string) error {
+	config, store := ctx.Config, ctx.Store
+	sourceDate, err := parseDate(args[0])
+	if err != nil {
+		return err
+	}
+
+	// Get source day data
+	sourceDay, ok, err := store.LoadDay(sourceDate)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		fmt.Printf("No data found for %s\n", sourceDate)
+		return nil
+	}
+	if len(sourceDay.Projects) == 0 {
+		fmt.Printf("No projects found for %s\n", sourceDate)
+		return nil
+	}
+
+	// Get target day data (will apply recurring meetings if new)
+	targetDay, err := getDateData(store, config, ctx.Date)
+	if err != nil {
+		return err
+	}
+
+	// Copy project totals from source to target; time ranges belong to
+	// the source day and aren't copied
+	for project, minutes := range sourceDay.Projects {
+		setProjectMinutes(&targetDay, project, minutes)
+	}
+
+	// Save the target day
+	if err := store.SaveDay(targetDay); err != nil {
+		return err
+	}
+
+	fmt.Printf("Copied %d project(s) from %s", len(sourceDay.Projects), sourceDate)
+	if ctx.Date != today() {
+		fmt.Printf(" to %s", ctx.Date)
+	} else {
+		fmt.Print(" to today")
+	}
+	fmt.Println()
+	ctx.status(targetDay)
+	return nil
+}
+
+func runExclude(ctx *Context, args []string) error {
+	day, err := getDateData(ctx.Store, ctx.Config, ctx.Date)
+	if err != nil {
+		return err
+	}
+	name := args[0]
+	hours, err := strconv.ParseFloat(args[1], 64)
+	if err != nil {
+		return fmt.Errorf("invalid hours: %s", args[1])
+	}
+	minutes := hoursToMinutes(hours)
+	if day.ExcludedMeetings == nil {
+		day.ExcludedMeetings = make(map[string]int)
+	}
+	day.ExcludedMeetings[name] = minutes
+
+	if err := ctx.Store.SaveDay(day); err != nil {
+		return err
+	}
+	fmt.Printf("Excluded %.1f%% for %s", toPercent(minutes, ctx.Config, day.Date), name)
+	ctx.onDate("on")
+	fmt.Println()
+	ctx.status(day)
+	return nil
+}
+
+func runRemove(ctx *Context, args []string) error {
+	config, store := ctx.Config, ctx.Store
+	project := resolveProject(args[0], config)
+
+	// Get data for target date
+	targetDay, err := getDateData(store, config, ctx.Date)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := targetDay.Projects[project]; !ok {
+		fmt.Printf("Project '%s' not found", project)
+		ctx.onDate("on")
+		fmt.Println()
+		return nil
+	}
+	removeProject(&targetDay, project)
+	if err := store.SaveDay(targetDay); err != nil {
+		return err
+	}
+	fmt.Printf("Removed %s", project)
+	ctx.onDate("from")
+	fmt.Println()
+	ctx.status(targetDay)
+	return nil
+}
+
+func runRemoveExcluded(ctx *Context, args []string) error {
+	day, err := getDateData(ctx.Store, ctx.Config, ctx.Date)
+	if err != nil {
+		return err
+	}
+	name := args[0]
+	if _, ok := day.ExcludedMeetings[name]; !ok {
+		fmt.Printf("Excluded meeting '%s' not found", name)
+		ctx.onDate("on")
+		fmt.Println()
+		return nil
+	}
+	delete(day.ExcludedMeetings, name)
+	if err := ctx.Store.SaveDay(day); err != nil {
+		return err
+	}
+	fmt.Printf("Removed excluded meeting: %s", name)
+	ctx.onDate("from")
+	fmt.Println()
+	ctx.status(day)
+	return nil
+}
+
+func runClear(ctx *Context, args []string) error {
+	what := "today's data"
+	if ctx.Date != today() {
+		what = "data for " + ctx.Date
+	}
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Printf("Clear %s? (y/n): ", what)
+	input, _ := reader.ReadString('\n')
+	if strings.TrimSpace(strings.ToLower(input)) == "y" {
+		if err := ctx.Store.DeleteDay(ctx.Date); err != nil {
+			return err
+		}
+		fmt.Printf("Cleared %s\n", what)
+	}
+	return nil
+}
+
+func runUndo(ctx *Context, args []string) error {
+	steps := 1
+	if len(args) >= 1 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			return errUsage
+		}
+		steps = n
+	}
+	if ctx.Command == "redo" {
+		return handleRedo(ctx.Base, ctx.Config, steps)
+	}
+	// Undoing one day is a change of its own, so it goes through the journal
+	if ctx.DateSet {
+		return handleUndoDay(ctx.Store, ctx.Config, ctx.Date, steps)
+	}
+	return handleUndo(ctx.Base, ctx.Config, steps)
+}
+
+func runLog(ctx *Context, args []string) error {
+	count := 10
+	if len(args) >= 1 {
+		if n, err := strconv.Atoi(args[0]); err == nil && n > 0 {
+			count = n
+		}
+	}
+	return printJournal(count)
+}
+
+func runStartTimer(ctx *Context, args []string) error {
+	allowNew, args := getNewFlag(args)
+	if len(args) < 1 {
+		return errUsage
+	}
+	project, err := resolveProjectInput(args[0], ctx.Store, ctx.Config, allowNew)
+	if err != nil {
+		return err
+	}
+	return startTimer(project)
+}
+
+func runSwitchTimer(ctx *Context, args []string) error {
+	allowNew, args := getNewFlag(args)
+	if len(args) < 1 {
+		return errUsage
+	}
+	project, err := resolveProjectInput(args[0], ctx.Store, ctx.Config, allowNew)
+	if err != nil {
+		return err
+	}
+	if _, _, err := stopTimer(ctx.Store, ctx.Config); err != nil {
+		return err
+	}
+	return startTimer(project)
+}
+
+func runStopTimer(ctx *Context, args []string) error {
+	stopped, days, err := stopTimer(ctx.Store, ctx.Config)
+	if err != nil {
+		return err
+	}
+	if stopped == nil {
+		fmt.Println("No timer running")
+		return nil
+	}
+	if len(days) > 0 {
+		ctx.status(days[len(days)-1])
+	}
+	return nil
+}
+
+func runTimer(ctx *Context, args []string) error {
+	if !ctx.JSON {
+		return printTimer(ctx.Config)
+	}
+	running, err := loadTimer()
+	if err != nil || running == nil {
+		if err == nil {
+			err = printJSON(nil)
+		}
+		return err
+	}
+	return printJSON(struct {
+		*Timer
+		Paused         bool `json:"paused"`
+		ElapsedMinutes int  `json:"elapsed_minutes"`
+	}{running, running.paused(), int(running.elapsed().Minutes())})
+}
+
+func runShow(ctx *Context, args []string) error {
+	r, args, err := parseRangeArgs(args)
+	if err != nil {
+		return err
+	}
+	days := 7
+	if len(args) >= 1 {
+		if d, err := strconv.Atoi(args[0]); err == nil {
+			days = d
+		}
+	}
+	var data map[string]DayData
+	if r != nil {
+		// Every day in the range, unless a number of days was also given
+		data, err = ctx.Store.Range(r.From, r.To)
+		if len(args) == 0 {
+			days = len(data)
+		}
+	} else {
+		data, err = loadRecentDays(ctx.Store, days)
+	}
+	if err != nil {
+		return err
+	}
+	if ctx.JSON {
+		list := make([]dayJSON, 0, len(data))
+		for _, date := range sortedKeys(data) {
+			list = append(list, newDayJSON(data[date], ctx.Config))
+		}
+		return printJSON(list)
+	}
+	printCalendar(data, ctx.Config, days)
+	return nil
+}
+
+func runNotes(ctx *Context, args []string) error {
+	data, err := ctx.Store.Range("", "")
+	if err != nil {
+		return err
+	}
+	query := strings.Join(args, " ")
+	if !ctx.JSON {
+		printNotes(data, query)
+		return nil
+	}
+
+	type noteJSON struct {
+		Date    string `json:"date"`
+		Project string `json:"project"`
+		Minutes int    `json:"minutes"`
+		Note    string `json:"note"`
+	}
+	notes := []noteJSON{}
+	for _, date := range sortedKeys(data) {
+		day := data[date]
+		for _, project := range sortedKeys(day.Notes) {
+			note := day.Notes[project]
+			if q := strings.ToLower(query); q != "" && !strings.Contains(strings.ToLower(note), q) &&
+				!strings.Contains(strings.ToLower(project), q) {
+				continue
+			}
+			notes = append(notes, noteJSON{date, project, day.Projects[project], note})
+		}
+	}
+	return printJSON(notes)
+}
+
+func runSearchCommand(ctx *Context, args []string) error {
+	opts, err := parseSearchArgs(args, ctx.Config)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return errUsage
+	}
+	if ctx.JSON {
+		results, err := findMatches(ctx.Store, opts)
+		if err != nil {
+			return err
+		}
+		return printJSON(results)
+	}
+	return runSearch(ctx.Store, opts)
+}
+
+func runReport(ctx *Context, args []string) error {
+	config, store := ctx.Config, ctx.Store
+	if !ctx.Quiet {
+		fmt.Println("Note: 'report' commands are deprecated. Use 'show' to view entries and 'export' for CSV reports.")
+		fmt.Println()
+	}
+
+	if len(args) < 1 {
+		fmt.Println("Usage:")
+		fmt.Println("  timetrack show [days]          - View calendar (recommended)")
+		fmt.Println("  timetrack export csv           - Export week to CSV")
+		fmt.Println("  timetrack export csv last-week - Export last week to CSV")
+		fmt.Println("  timetrack export all           - Export all data")
+		return nil
+	}
+
+	reportType := strings.ToLower(args[0])
+	switch reportType {
+	case "week", "weekly", "project", "proj", "stats", "statistics":
+	default:
+		return fmt.Errorf("unknown report type %q (use week, project or stats; or try: timetrack show [days])", reportType)
+	}
+	groupBy, reportArgs, err := parseGroupFlag(args[1:])
+	if err != nil {
+		return err
+	}
+	r, reportArgs, err := parseRangeArgs(reportArgs)
+	if err != nil {
+		return err
+	}
+	if r == nil {
+		if reportType == "week" || reportType == "weekly" {
+			week := thisWeek()
+			r = &week
+		} else {
+			r = &dayRange{Label: "all data"}
+		}
+	}
+	data, err := store.Range(r.From, r.To)
+	if err != nil {
+		return err
+	}
+
+	switch reportType {
+	case "week", "weekly":
+		generateWeeklyReport(data, config, *r, groupBy)
+	case "project", "proj":
+		if len(reportArgs) < 1 {
+			return errUsage
+		}
+		generateProjectReport(data, reportArgs[0], config)
+	case "stats", "statistics":
+		generateStatsReport(data, config, groupBy)
+	}
+	return nil
+}
+
+func runExport(ctx *Context, args []string) error {
+	config, store := ctx.Config, ctx.Store
+	r, args, err := parseRangeArgs(args)
+	if err != nil {
+		return err
+	}
+	format := "csv"
+	if len(args) >= 1 {
+		format = strings.ToLower(args[0])
+		args = args[1:]
+	}
+	switch format {
+	case "json", "csv", "week", "xlsx", "all":
+	default:
+		return fmt.Errorf("unknown export format %q (use csv, all, json or xlsx)", format)
+	}
+	groupBy, exportArgs, err := parseGroupFlag(args)
+	if err != nil {
+		return err
+	}
+	per, exportArgs, err := parseSheetsFlag(exportArgs)
+	if err != nil {
+		return err
+	}
+
+	// csv and xlsx default to the current week, everything else to all data
+	if r == nil {
+		if format == "csv" || format == "week" || format == "xlsx" {
+			week := thisWeek()
+			r = &week
+		} else {
+			r = &dayRange{Label: "all data"}
+		}
+	}
+	data, err := store.Range(r.From, r.To)
+	if err != nil {
+		return fmt.Errorf("export failed: %w", err)
+	}
+	// Like show, a range without any tracked days has nothing to export
+	if len(data) == 0 {
+		fmt.Printf("No tracked time found for %s; nothing exported\n", r.Label)
+		return nil
+	}
+
+	filename := ""
+	if len(exportArgs) >= 1 {
+		filename = exportArgs[0]
+	}
+	switch format {
+	case "json":
+		if filename == "" {
+			filename = "timetrack-export.json"
+		}
+		err = exportToJSON(data, filename)
+	case "csv", "week":
+		if filename == "" {
+			filename = "timetrack-week.csv"
+		}
+		err = exportRangeToCSV(data, config, *r, filename, groupBy)
+	case "xlsx":
+		if filename == "" {
+			filename = "timetrack.xlsx"
+		}
+		err = exportToXLSX(data, config, *r, filename, groupBy, per)
+	case "all":
+		if filename == "" {
+			filename = "timetrack-all.csv"
+		}
+		err = exportAllToCSV(data, config, r.Label, filename, groupBy)
+	}
+	if err != nil {
+		return fmt.Errorf("export failed: %w", err)
+	}
+	return nil
+}
+
+func runImport(ctx *Context, args []string) error {
+	if args[0] == "ics" || strings.EqualFold(filepath.Ext(args[0]), ".ics") {
+		return runImportICS(ctx, args)
+	}
+	if err := importFile(args[0], ctx.Config, ctx.Store); err != nil {
+		return fmt.Errorf("import failed: %w", err)
+	}
+	return nil
+}
+
+func runImportICS(ctx *Context, args []string) error {
+	if args[0] == "ics" {
+		args = args[1:]
+	}
+	r, args, err := parseRangeArgs(args)
+	if err != nil {
+		return err
+	}
+	dryRun := false
+	var files []string
+	for _, arg := range args {
+		if arg == "--dry-run" {
+			dryRun = true
+		} else {
+			files = append(files, arg)
+		}
+	}
+	if len(files) != 1 {
+		return errUsage
+	}
+	if r == nil {
+		week := thisWeek()
+		r = &week
+	}
+	if err := importICS(files[0], *r, ctx.Config, ctx.Store, dryRun); err != nil {
+		return fmt.Errorf("import failed: %w", err)
+	}
+	return nil
+}
+
+func runBackup(ctx *Context, args []string) error {
+	if len(args) >= 1 && args[0] != "list" {
+		return errUsage
+	}
+	return printBackupList()
+}
+
+func runRestore(ctx *Context, args []string) error {
+	if err := restoreBackup(ctx.Store, args[0]); err != nil {
+		return fmt.Errorf("restore failed: %w", err)
+	}
+	return nil
+}
+
+func runProjects(ctx *Context, args []string) error {
+	config, store := ctx.Config, ctx.Store
+	if len(args) < 1 {
+		return errUsage
+	}
+	subcmd := args[0]
+	if ctx.JSON && subcmd != "list" {
+		return fmt.Errorf("--json is only supported by 'projects list'")
+	}
+	switch subcmd {
+	case "set":
+		if len(args) < 2 {
+			fmt.Println("Usage: timetrack projects set \"Proj1,Proj2,Proj3,...\"")
+			return nil
+		}
+		projects := strings.Split(args[1], ",")
+		for i, p := range projects {
+			projects[i] = strings.TrimSpace(p)
+		}
+		config.Projects = projects
+		if err := saveConfig(config); err != nil {
+			return err
+		}
+		fmt.Printf("Set %d project columns\n", len(projects))
+	case "parse":
+		if len(args) < 2 {
+			fmt.Println("Usage: timetrack projects parse \"Date,Proj1,Proj2,...,Total Time Spent\"")
+			fmt.Println("Paste the full header row from Excel - first and last columns are stripped automatically")
+			return nil
+		}
+		cols := strings.Split(args[1], ",")
+		for i, p := range cols {
+			cols[i] = strings.TrimSpace(p)
+		}
+		// Strip first and last columns
+		if len(cols) > 2 {
+			cols = cols[1 : len(cols)-1]
+		}
+		config.Projects = cols
+
+		// Auto-generate aliases
+		if config.Aliases == nil {
+			config.Aliases = make(map[string]string)
+		}
+		for _, project := range cols {
+			alias := generateAlias(project, config.Aliases)
+			config.Aliases[alias] = project
+		}
+
+		if err := saveConfig(config); err != nil {
+			return err
+		}
+		fmt.Printf("Set %d project columns with aliases:\n", len(cols))
+		for _, p := range cols {
+			// Find alias for this project
+			for alias, name := range config.Aliases {
+				if name == p {
+					fmt.Printf("  %s → %s\n", alias, p)
+					break
+				}
+			}
+		}
+	case "list":
+		// Auto-discover projects from all tracked time
+		projects, err := store.Projects()
+		if err != nil {
+			return err
+		}
+		if ctx.JSON {
+			type projectJSON struct {
+				Name     string       `json:"name"`
+				Archived bool         `json:"archived,omitempty"`
+				Meta     *ProjectMeta `json:"meta,omitempty"`
+			}
+			list := []projectJSON{}
+			for _, p := range projects {
+				entry := projectJSON{Name: p, Archived: isArchived(p, config)}
+				if meta, ok := config.ProjectInfo[p]; ok {
+					entry.Meta = &meta
+				}
+				list = append(list, entry)
+			}
+			return printJSON(list)
+		}
+		if len(projects) == 0 {
+			fmt.Println("No projects found in tracked time")
+			return nil
+		}
+		fmt.Println("Projects (auto-discovered from your time tracking):")
+		for i, p := range projects {
+			fmt.Printf("%d. %s", i+1, p)
+			if meta := config.ProjectInfo[p]; !meta.isEmpty() {
+				fmt.Printf("  %s(%s)%s", ColorGray, meta, ColorReset)
+			}
+			if isArchived(p, config) {
+				fmt.Printf("  %s[archived]%s", ColorGray, ColorReset)
+			}
+			fmt.Println()
+		}
+	case "meta", "info":
+		return handleProjectMeta(config, args[1:])
+	case "rename", "merge":
+		if len(args) < 3 {
+			return errUsage
+		}
+		from := resolveProject(args[1], config)
+		to := strings.TrimSpace(args[2])
+		if subcmd == "merge" {
+			to = resolveProject(to, config)
+		}
+		return renameProject(store, config, from, to, subcmd == "merge")
+	case "archive":
+		return handleProjectArchive(config, args[1:], true)
+	case "unarchive":
+		return handleProjectArchive(config, args[1:], false)
+	default:
+		return errUsage
+	}
+	return nil
+}
+
+func runAlias(ctx *Context, args []string) error {
+	config := ctx.Config
+	if args[0] == "list" {
+		if len(config.Aliases) == 0 {
+			fmt.Println("No aliases configured")
+			return nil
+		}
+		for _, k := range sortedKeys(config.Aliases) {
+			fmt.Printf("%s → %s\n", k, config.Aliases[k])
+		}
+		return nil
+	}
+	if args[0] == "rm" && len(args) >= 2 {
+		short := strings.ToLower(args[1])
+		if _, ok := config.Aliases[short]; !ok {
+			fmt.Printf("Alias '%s' not found\n", short)
+			return nil
+		}
+		delete(config.Aliases, short)
+		if err := saveConfig(config); err != nil {
+			return err
+		}
+		fmt.Printf("Removed alias: %s\n", short)
+		return nil
+	}
+	if len(args) < 2 {
+		return errUsage
+	}
+	short := strings.ToLower(args[0])
+	full := strings.Join(args[1:], " ")
+	config.Aliases[short] = full
+	if err := saveConfig(config); err != nil {
+		return err
+	}
+	fmt.Printf("Alias set: %s → %s\n", short, full)
+	return nil
+}
+
+func runConfig(ctx *Context, args []string) error {
+	config := ctx.Config
+	if len(args) == 0 {
+		printConfig(config)
+		return nil
+	}
+
+	switch args[0] {
+	case "edit":
+		// Runs without the data lock (see NoLockArgs), so other commands
+		// aren't held up for as long as the editor is open
+		if !fileExists(getConfigPath()) {
+			if _, err := loadConfig(); err != nil {
+				return err
+			}
+		}
+		var cmd *exec.Cmd
+		if runtime.GOOS == "windows" {
+			cmd = exec.Command("notepad", getConfigPath())
+		} else {
+			editor := os.Getenv("EDITOR")
+			if editor == "" {
+				editor = "nano"
+			}
+			cmd = exec.Command(editor, getConfigPath())
+		}
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("editor failed: %w", err)
+		}
+	case "schedule":
+		return handleSchedule(config, args[1:])
+	case "strict":
+		if len(args) < 2 || (args[1] != "on" && args[1] != "off") {
+			fmt.Println("Usage: timetrack config strict <on|off>")
+			return nil
+		}
+		config.StrictProjects = args[1] == "on"
+		if err := saveConfig(config); err != nil {
+			return err
+		}
+		if config.StrictProjects {
+			fmt.Println("Strict project mode on: unknown projects need --new")
+		} else {
+			fmt.Println("Strict project mode off")
+		}
+	case "timesheet":
+		return handleTimesheet(config, args[1:])
+	case "csv-columns":
+		if len(args) < 2 {
+			fmt.Println("Usage: timetrack config csv-columns <date,projects,total,notes>|reset")
+			return nil
+		}
+		if args[1] == "reset" {
+			config.CSVColumns = nil
+		} else {
+			layout, err := parseCSVLayout(strings.Join(args[1:], ","))
+			if err != nil {
+				return err
+			}
+			config.CSVColumns = layout
+		}
+		if err := saveConfig(config); err != nil {
+			return err
+		}
+		fmt.Printf("CSV columns: %s\n", strings.Join(csvLayout(config, true), ", "))
+	case "day-hours":
+		if len(args) < 2 {
+			fmt.Println("Usage: timetrack config day-hours <hours>")
+			return nil
+		}
+		hours, err := strconv.ParseFloat(args[1], 64)
+		if err != nil || hours <= 0 || hours > 24 {
+			return fmt.Errorf("invalid hours: %s", args[1])
+		}
+		config.DayHours = hours
+		if err := saveConfig(config); err != nil {
+			return err
+		}
+		fmt.Printf("Working day set to %s\n", formatDuration(getDayMinutes(config, "")))
+	default:
+		printConfig(config)
+	}
+	return nil
+}
+
+func runMeeting(ctx *Context, args []string) error {
+	config := ctx.Config
+	switch args[0] {
+	case "add":
+		if len(args) < 4 {
+			fmt.Println("Usage: timetrack meeting add <name> <hours> <days>")
+			fmt.Println("Days: mon,tue,wed,thu,fri,sat,sun,daily,weekdays")
+			return nil
+		}
+		name := args[1]
+		hours, err := strconv.ParseFloat(args[2], 64)
+		if err != nil {
+			return fmt.Errorf("invalid hours: %s", args[2])
+		}
+		minutes := hoursToMinutes(hours)
+		days := strings.Split(strings.ToLower(args[3]), ",")
+
+		// Check if meeting already exists, update it
+		found := false
+		for i, m := range config.RecurringMeetings {
+			if m.Name == name {
+				config.RecurringMeetings[i].Minutes = minutes
+				config.RecurringMeetings[i].Days = days
+				found = true
+				break
+			}
+		}
+		if !found {
+			config.RecurringMeetings = append(config.RecurringMeetings, RecurringMeeting{
+				Name:    name,
+				Minutes: minutes,
+				Days:    days,
+			})
+		}
+		if err := saveConfig(config); err != nil {
+			return err
+		}
+		fmt.Printf("Added recurring meeting: %s (%s on %s)\n", name, formatDuration(minutes), strings.Join(days, ", "))
+
+	case "rm", "remove":
+		if len(args) < 2 {
+			fmt.Println("Usage: timetrack meeting rm <name>")
+			return nil
+		}
+		name := args[1]
+		found := false
+		for i, m := range config.RecurringMeetings {
+			if m.Name == name {
+				config.RecurringMeetings = append(config.RecurringMeetings[:i], config.RecurringMeetings[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			fmt.Printf("Meeting '%s' not found\n", name)
+			return nil
+		}
+		if err := saveConfig(config); err != nil {
+			return err
+		}
+		fmt.Printf("Removed recurring meeting: %s\n", name)
+
+	case "rule":
+		return handleCalendarRule(config, args[1:])
+
+	case "rules":
+		printCalendarRules(config)
+
+	default:
+		return errUsage
+	}
+	return nil
+}
+
+func runReminder(ctx *Context, args []string) error {
+	config := ctx.Config
+	if len(args) < 1 {
+		fmt.Println("Current reminder times:", strings.Join(config.ReminderTimes, ", "))
+		fmt.Println("Usage: timetrack reminder 09:00,12:00,15:00")
+		return nil
+	}
+	times := strings.Split(args[0], ",")
+	config.ReminderTimes = times
+	if err := saveConfig(config); err != nil {
+		return err
+	}
+	fmt.Println("Reminder times set to:", strings.Join(times, ", "))
+	return nil
+}
+
+func runURL(ctx *Context, args []string) error {
+	config := ctx.Config
+	if len(args) < 1 {
+		if config.TimesheetURL == "" {
+			fmt.Println("No timesheet URL configured")
+			fmt.Println("Usage: timetrack url set <url>")
+		} else {
+			fmt.Println("Timesheet URL:", config.TimesheetURL)
+		}
+		return nil
+	}
+
+	subcmd := strings.ToLower(args[0])
+	switch subcmd {
+	case "set":
+		if len(args) < 2 {
+			return errUsage
+		}
+		config.TimesheetURL = args[1]
+		if err := saveConfig(config); err != nil {
+			return err
+		}
+		fmt.Println("Timesheet URL set to:", config.TimesheetURL)
+
+	case "open":
+		if config.TimesheetURL == "" {
+			fmt.Println("No timesheet URL configured")
+			fmt.Println("Use: timetrack url set <url>")
+			return nil
+		}
+		openURL(config.TimesheetURL)
+
+	case "rm", "remove", "clear":
+		config.TimesheetURL = ""
+		if err := saveConfig(config); err != nil {
+			return err
+		}
+		fmt.Println("Timesheet URL cleared")
+
+	default:
+		return errUsage
+	}
+	return nil
+}
+
+func runStorage(ctx *Context, args []string) error {
+	config := ctx.Config
+	if len(args) < 1 {
+		fmt.Println("Storage backend:", storageBackend(config))
+		fmt.Println("Usage: timetrack storage <json|sqlite>")
+		return nil
+	}
+	target := strings.ToLower(args[0])
+	if target == storageBackend(config) {
+		fmt.Println("Already using", target, "storage")
+		return nil
+	}
+	if err := migrateStorage(config, target); err != nil {
+		return fmt.Errorf("storage switch failed: %w", err)
+	}
+	config.Storage = target
+	if err := saveConfig(config); err != nil {
+		return err
+	}
+	fmt.Println("Storage backend set to:", target)
+	return nil
+}
+
+// runMigrateCommand inspects the files before loading them, since loading
+// upgrades them in memory
+func runMigrateCommand(ctx *Context, args []string) error {
+	dryRun := len(args) >= 1 && args[0] == "--dry-run"
+	if err := runMigrate(dryRun); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+	return nil
+}
+
+func runStartDaemon(ctx *Context, args []string) error {
+	if isDaemonRunning() {
+		fmt.Println("Daemon is already running")
+		return nil
+	}
+	runDaemon()
+	return nil
+}
+
+func runStartDaemonBackground(ctx *Context, args []string) error {
+	if isDaemonRunning() {
+		fmt.Println("Daemon is already running")
+		return nil
+	}
+	exe, _ := os.Executable()
+	daemonArgs := []string{"start"}
+	if dataDirOverride != "" {
+		daemonArgs = append(daemonArgs, "--data-dir", dataDirOverride)
+	}
+	cmd := exec.Command(exe, daemonArgs...)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start reminder service: %w", err)
+	}
+	fmt.Println("Reminder service started in background")
+	fmt.Printf("PID: %d\n", cmd.Process.Pid)
+	return nil
+}
+
+func runDaemonStatus(ctx *Context, args []string) error {
+	if isDaemonRunning() {
+		pidBytes, _ := os.ReadFile(getPidPath())
+		fmt.Printf("Reminder service is running (PID: %s)\n", strings.TrimSpace(string(pidBytes)))
+	} else {
+		fmt.Println("Reminder service is not running")
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// completionSubcommands are offered as the word after a command
+var completionSubcommands = map[string][]string{
+	"export":     {"csv", "week", "all", "json", "xlsx"},
+	"report":     {"week", "project", "stats"},
+	"projects":   {"list", "meta", "rename", "merge", "archive", "unarchive", "set", "parse"},
+	"config":     {"edit", "schedule", "strict", "day-hours", "csv-columns", "timesheet"},
+	"leave":      {"add", "rm", "list", "import"},
+	"import":     {"ics"},
+	"alias":      {"rm", "list"},
+	"meeting":    {"add", "rm", "rule", "rules"},
+	"storage":    {"json", "sqlite"},
+	"backup":     {"list"},
+	"url":        {"set", "open", "rm"},
+	"completion": {"bash", "zsh", "fish"},
+}
+
+// projectCommands take a project as their first argument
+var projectCommands = map[string]bool{
+	"add": true, "fill": true, "edit": true, "rm": true, "start-timer": true, "switch": true,
+}
+
+// completeWords returns the candidates for the last of words, the words
+// typed after "timetrack" with the one being completed last (possibly "")
+func completeWords(words []string, config Config, projects []string) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	cur := unquoteWord(words[len(words)-1])
+	prev := ""
+	if len(words) >= 2 {
+		prev = words[len(words)-2]
+	}
+	if len(words) == 1 {
+		return filterPrefix(commandNames(), cur)
+	}
+
+	cmd := strings.ToLower(words[0])
+	switch prev {
+	case "--date", "-d", "--from", "--to":
+		return filterPrefix(recentDates(), cur)
+	case "--project", "-p":
+		return filterPrefix(projectCompletions(config, projects), cur)
+	case "--by", "--group":
+		return filterPrefix(projectGroupings, cur)
+	case "--sheets":
+		return filterPrefix([]string{sheetsByWeek, sheetsByMonth}, cur)
+	case "--week":
+		return filterPrefix(recentWeeks(), cur)
+	case "--month":
+		return filterPrefix(recentMonths(), cur)
+	case "-m", "--message", "--min", "--data-dir":
+		return nil
+	}
+	if strings.HasPrefix(cur, "-") {
+		var flags []string
+		if c := findCommand(cmd); c != nil {
+			flags = append(flags, c.Flags...)
+			if c.Date {
+				flags = append(flags, "--date")
+			}
+			if c.JSON {
+				flags = append(flags, "--json")
+			}
+		}
+		flags = append(flags, "--quiet", "--data-dir")
+		return filterPrefix(flags, cur)
+	}
+
+	// Positional arguments, skipping flags and their values
+	var args []string
+	for i := 1; i < len(words)-1; i++ {
+		switch words[i] {
+		case "--date", "-d", "--from", "--to", "--week", "--month", "--sheets", "--project", "-p", "--by", "--group", "-m", "--message", "--min", "--data-dir":
+			i++
+		case "--new", "--json", "--quiet", "-q", "--dry-run":
+		default:
+			args = append(args, words[i])
+		}
+	}
+
+	if subs, ok := completionSubcommands[cmd]; ok && len(args) == 0 {
+		return filterPrefix(subs, cur)
+	}
+	switch {
+	case projectCommands[cmd] && len(args) == 0:
+		return filterPrefix(projectCompletions(config, projects), cur)
+	case (cmd == "show" || cmd == "cal" || cmd == "calendar") && len(args) == 0:
+		return filterPrefix(rangeKeywords, cur)
+	case (cmd == "export" || cmd == "report") && len(args) == 1 && args[0] != "project":
+		return filterPrefix(rangeKeywords, cur)
+	case cmd == "import" && len(args) == 2 && args[0] == "ics":
+		return filterPrefix(rangeKeywords, cur)
+	case cmd == "report" && len(args) == 1 && args[0] == "project":
+		return filterPrefix(projectCompletions(config, projects), cur)
+	case cmd == "alias" && len(args) == 1 && args[0] != "rm" && args[0] != "list":
+		return filterPrefix(projectCompletions(config, projects), cur)
+	case cmd == "alias" && len(args) == 1 && args[0] == "rm":
+		return filterPrefix(sortedKeys(config.Aliases), cur)
+	case cmd == "projects" && len(args) >= 1:
+		switch args[0] {
+		case "meta", "archive", "unarchive", "rename":
+			if len(args) == 1 {
+				return filterPrefix(projectCompletions(config, projects), cur)
+			}
+		case "merge":
+			if len(args) <= 2 {
+				return filterPrefix(projectCompletions(config, projects), cur)
+			}
+		}
+	}
+	return nil
+}
+
+// projectCompletions lists every project, then aliases not already listed
+func projectCompletions(config Config, projects []string) []string {
+	var names []string
+	for _, p := range knownProjects(projects, config) {
+		if !isArchived(p, config) {
+			names = append(names, p)
+		}
+	}
+	for _, alias := range sortedKeys(config.Aliases) {
+		if !containsName(names, alias) {
+			names = append(names, alias)
+		}
+	}
+	return names
+}
+
+// recentWeeks offers this week and the four before it as ISO weeks
+func recentWeeks() []string {
+	var weeks []string
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		year, week := now.AddDate(0, 0, -7*i).ISOWeek()
+		weeks = append(weeks, fmt.Sprintf("%d-W%02d", year, week))
+	}
+	return weeks
+}
+
+// recentMonths offers this month and the two before it
+func recentMonths() []string {
+	first := time.Date(time.Now().Year(), time.Now().Month(), 1, 0, 0, 0, 0, time.Local)
+	var months []string
+	for i := 0; i < 3; i++ {
+		months = append(months, first.AddDate(0, -i, 0).Format("2006-01"))
+	}
+	return months
+}
+
+// recentDates offers today, yesterday and the week before that
+func recentDates() []string {
+	dates := []string{"today", "yesterday"}
+	now := time.Now()
+	for i := 0; i < 7; i++ {
+		dates = append(dates, now.AddDate(0, 0, -i).Format("2006-01-02"))
+	}
+	return dates
+}
+
+// unquoteWord strips the shell quoting from a partly typed word, so
+// "\"CT.G" and CT\ Au match project names
+func unquoteWord(word string) string {
+	word = strings.TrimLeft(word, `"'`)
+	return strings.ReplaceAll(word, `\`, "")
+}
+
+func filterPrefix(options []string, prefix string) []string {
+	prefix = strings.ToLower(prefix)
+	var matches []string
+	for _, o := range options {
+		if strings.HasPrefix(strings.ToLower(o), prefix) {
+			matches = append(matches, o)
+		}
+	}
+	return matches
+}
+
+// runComplete implements the hidden "__complete" command the completion
+// scripts call. It prints one candidate per line and nothing on error. It
+// runs on every tab press, so it takes no lock and reads only the project
+// names.
+func runComplete(words []string) {
+	config, _, err := readConfig()
+	if err != nil {
+		return
+	}
+	var projects []string
+	if store, err := openStoreReadOnly(config); err == nil {
+		projects, _ = store.Projects()
+		store.Close()
+	}
+	for _, candidate := range completeWords(words, config, projects) {
+		fmt.Println(candidate)
+	}
+}
+
+// printCompletionScript writes the completion script for shell
+func printCompletionScript(shell string) error {
+	script, ok := completionScripts[shell]
+	if !ok {
+		return fmt.Errorf("unsupported shell %q (use bash, zsh or fish)", shell)
+	}
+	_, err := os.Stdout.WriteString(script)
+	return err
+}
+
+var completionScripts = map[string]string{
+	"bash": `# timetrack completion for bash
+# Add to ~/.bashrc:  source <(timetrack completion bash)
+_timetrack() {
+    local cur=${COMP_WORDS[COMP_CWORD]}
+    local IFS=$'\n'
+    local candidates=($(timetrack __complete "${COMP_WORDS[@]:1:COMP_CWORD-1}" "$cur" 2>/dev/null))
+    local c
+    COMPREPLY=()
+    for c in "${candidates[@]}"; do
+        COMPREPLY+=("$(printf '%q' "$c")")
+    done
+}
+complete -F _timetrack timetrack
+`,
+	"zsh": `#compdef timetrack
+# timetrack completion for zsh
+# Add to ~/.zshrc:  source <(timetrack completion zsh)
+_timetrack() {
+    local -a candidates
+    candidates=("${(@f)$(timetrack __complete "${(@)words[2,CURRENT-1]}" "${words[CURRENT]}" 2>/dev/null)}")
+    compadd -a candidates
+}
+compdef _timetrack timetrack
+`,
+	"fish": `# timetrack completion for fish
+# Save as ~/.config/fish/completions/timetrack.fish, or run:
+#   timetrack completion fish | source
+complete -c timetrack -f -a '(timetrack __complete (commandline -opc)[2..-1] (commandline -ct) 2>/dev/null)'
+`,
+}
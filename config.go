@@ -2,29 +2,64 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 )
 
-func loadConfig() Config {
+// loadConfig reads the config, writing the defaults out if there's none yet
+func loadConfig() (Config, error) {
+	config, exists, err := readConfig()
+	if err != nil || exists {
+		return config, err
+	}
+	return config, saveConfig(config)
+}
+
+// readConfig reads the config without writing anything, for callers that
+// don't hold the data lock. With no config file it returns the defaults.
+func readConfig() (Config, bool, error) {
 	config := Config{
 		ReminderTimes:     []string{"09:00", "12:00", "15:00"},
 		RecurringMeetings: []RecurringMeeting{},
 		Projects:          []string{},
 		Aliases:           make(map[string]string),
 	}
-	bytes, err := os.ReadFile(getConfigPath())
+	bytes, err := readConfigFile()
+	if err != nil || bytes == nil {
+		return config, false, err
+	}
+
+	// Older configs are upgraded in memory; the new version is written on the next save
+	bytes, _, err = migrateConfigBytes(bytes)
 	if err != nil {
-		saveConfig(config)
-		return config
+		return config, true, err
+	}
+	if err := json.Unmarshal(bytes, &config); err != nil {
+		return config, true, fmt.Errorf("failed to parse %s: %w", getConfigPath(), err)
 	}
-	json.Unmarshal(bytes, &config)
 	if config.Aliases == nil {
 		config.Aliases = make(map[string]string)
 	}
-	return config
+	return config, true, nil
 }
 
-func saveConfig(config Config) {
-	bytes, _ := json.MarshalIndent(config, "", "  ")
-	os.WriteFile(getConfigPath(), bytes, 0644)
+// readConfigFile returns the raw config, or nil if none has been written yet
+func readConfigFile() ([]byte, error) {
+	bytes, err := os.ReadFile(getConfigPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", getConfigPath(), err)
+	}
+	return bytes, nil
+}
+
+func saveConfig(config Config) error {
+	config.SchemaVersion = configSchemaVersion
+	bytes, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	return writeFileAtomic(getConfigPath(), bytes, 0644)
 }
@@ -11,7 +11,11 @@ import (
 )
 
 func runDaemon() {
-	config := loadConfig()
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
 
 	fmt.Println("TimeTrack reminder service started")
 	fmt.Printf("Reminder times: %v\n", config.ReminderTimes)
@@ -37,19 +41,24 @@ func runDaemon() {
 			if now == reminderTime && !notifiedToday[reminderTime] {
 				notifiedToday[reminderTime] = true
 
-				data := loadData()
-				day := getTodayData(data, config)
-				available := getAvailablePercent(day)
-				tracked := getTotalTracked(day)
-				remaining := available - tracked
+				day, err := loadTodayForReminder(config)
+				if err == nil && !isWorkingDay(day, config) {
+					// Nothing to track on holidays, leave or unscheduled days
+					continue
+				}
+				remaining := getAvailableMinutes(day, config) - getTotalTracked(day)
 
 				var message string
-				if remaining > 0 {
-					message = fmt.Sprintf("%.1f%% remaining to track today", remaining)
+				if err != nil {
+					message = "Time to update your timesheet"
+				} else if remaining > 0 {
+					message = fmt.Sprintf("%.1f%% (%s) remaining to track today",
+						toPercent(remaining, config, day.Date), formatDuration(remaining))
 				} else if remaining == 0 {
 					message = "Day fully tracked! ✨"
 				} else {
-					message = fmt.Sprintf("Over-allocated by %.1f%%", -remaining)
+					message = fmt.Sprintf("Over-allocated by %.1f%% (%s)",
+						toPercent(-remaining, config, day.Date), formatDuration(-remaining))
 				}
 
 				sendNotification("⏰ TimeTrack Reminder", message)
@@ -60,6 +69,23 @@ func runDaemon() {
 	}
 }
 
+// loadTodayForReminder opens the store just long enough to read today, under
+// a shared lock so it never sees a command's half-finished update
+func loadTodayForReminder(config Config) (DayData, error) {
+	lock, err := acquireLock(false, reminderLockTimeout)
+	if err != nil {
+		return DayData{}, err
+	}
+	defer lock.Release()
+
+	store, err := openStore(config)
+	if err != nil {
+		return DayData{}, err
+	}
+	defer store.Close()
+	return getTodayData(store, config)
+}
+
 func isDaemonRunning() bool {
 	pidBytes, err := os.ReadFile(getPidPath())
 	if err != nil {
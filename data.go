@@ -3,24 +3,71 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"strings"
 	"time"
 )
 
-func loadData() map[string]DayData {
-	data := make(map[string]DayData)
-	bytes, err := os.ReadFile(getDataPath())
+// dataFile is the layout of data.json. Before schema version 2 the file was
+// just the map of days, with no version.
+type dataFile struct {
+	SchemaVersion int                `json:"schema_version"`
+	Days          map[string]DayData `json:"days"`
+}
+
+func loadData(path string) (map[string]DayData, error) {
+	data, _, err := readDataFile(path)
+	return data, err
+}
+
+// readDataFile loads a data file, upgrading older schema versions in memory.
+// The report says which migrations were needed.
+func readDataFile(path string) (map[string]DayData, migrationReport, error) {
+	current := migrationReport{From: dataSchemaVersion, To: dataSchemaVersion}
+	bytes, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return make(map[string]DayData), current, nil
+	}
 	if err != nil {
-		return data
+		return nil, current, fmt.Errorf("failed to read %s: %w", path, err)
 	}
-	json.Unmarshal(bytes, &data)
-	return data
+
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(bytes, &top); err != nil {
+		return nil, current, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	version := 1
+	raw := top
+	if v, ok := top["schema_version"]; ok {
+		if err := json.Unmarshal(v, &version); err != nil {
+			return nil, current, fmt.Errorf("failed to parse %s: invalid schema_version", path)
+		}
+		raw = make(map[string]json.RawMessage)
+		if days, ok := top["days"]; ok {
+			if err := json.Unmarshal(days, &raw); err != nil {
+				return nil, current, fmt.Errorf("failed to parse %s: %w", path, err)
+			}
+		}
+	}
+	if err := checkSchemaVersion(path, version, dataSchemaVersion); err != nil {
+		return nil, current, err
+	}
+
+	data, report, err := decodeDays(raw, version)
+	if err != nil {
+		return nil, report, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return data, report, nil
 }
 
-func saveData(data map[string]DayData) {
-	bytes, _ := json.MarshalIndent(data, "", "  ")
-	os.WriteFile(getDataPath(), bytes, 0644)
+func saveData(path string, data map[string]DayData) error {
+	bytes, err := json.MarshalIndent(dataFile{SchemaVersion: dataSchemaVersion, Days: data}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode data: %w", err)
+	}
+	return writeFileAtomic(path, bytes, 0644)
 }
 
 func today() string {
@@ -32,6 +79,12 @@ func parseDate(dateStr string) (string, error) {
 	if dateStr == "" {
 		return today(), nil
 	}
+	switch strings.ToLower(dateStr) {
+	case "today":
+		return today(), nil
+	case "yesterday":
+		return time.Now().AddDate(0, 0, -1).Format("2006-01-02"), nil
+	}
 
 	// Try UK date formats only (plus ISO standard)
 	formats := []string{
@@ -75,30 +128,27 @@ func getTargetDate(args []string, flagName string) (string, []string, error) {
 	return targetDate, remainingArgs, nil
 }
 
-func todayWeekday() string {
-	return strings.ToLower(time.Now().Weekday().String()[:3])
-}
-
-func isWeekday() bool {
-	day := time.Now().Weekday()
-	return day >= time.Monday && day <= time.Friday
-}
-
-func getTodayData(data map[string]DayData, config Config) DayData {
-	return getDateData(data, config, today())
+func getTodayData(store Store, config Config) (DayData, error) {
+	return getDateData(store, config, today())
 }
 
-func getDateData(data map[string]DayData, config Config, date string) DayData {
-	if d, ok := data[date]; ok {
-		return d
+func getDateData(store Store, config Config, date string) (DayData, error) {
+	d, ok, err := store.LoadDay(date)
+	if err != nil {
+		return DayData{}, err
+	}
+	if ok {
+		return d, nil
 	}
+	return newDay(config, date), nil
+}
 
-	// New day - apply recurring meetings
+// newDay creates an empty day with the recurring meetings for its weekday applied
+func newDay(config Config, date string) DayData {
 	day := DayData{
 		Date:             date,
-		ExcludedPercent:  0,
-		Projects:         make(map[string]float64),
-		ExcludedMeetings: make(map[string]float64),
+		Projects:         make(map[string]int),
+		ExcludedMeetings: make(map[string]int),
 	}
 
 	// Parse the date to get weekday
@@ -109,8 +159,7 @@ func getDateData(data map[string]DayData, config Config, date string) DayData {
 
 		for _, meeting := range config.RecurringMeetings {
 			if shouldApplyMeetingForDate(meeting, weekday, isWeekdayDate) {
-				day.ExcludedMeetings[meeting.Name] = meeting.Percent
-				day.ExcludedPercent += meeting.Percent
+				day.ExcludedMeetings[meeting.Name] = meeting.Minutes
 			}
 		}
 	}
@@ -134,34 +183,138 @@ func shouldApplyMeetingForDate(meeting RecurringMeeting, weekday string, isWeekd
 	return false
 }
 
-func shouldApplyMeeting(meeting RecurringMeeting, weekday string) bool {
-	for _, d := range meeting.Days {
-		d = strings.ToLower(d)
-		if d == weekday {
-			return true
+// cloneDay returns a copy of day that shares no maps with the original
+func cloneDay(day DayData) DayData {
+	clone := day
+	clone.Projects = make(map[string]int, len(day.Projects))
+	for k, v := range day.Projects {
+		clone.Projects[k] = v
+	}
+	clone.ExcludedMeetings = make(map[string]int, len(day.ExcludedMeetings))
+	for k, v := range day.ExcludedMeetings {
+		clone.ExcludedMeetings[k] = v
+	}
+	if day.Notes != nil {
+		clone.Notes = make(map[string]string, len(day.Notes))
+		for k, v := range day.Notes {
+			clone.Notes[k] = v
 		}
-		if d == "daily" {
-			return true
+	}
+	if day.Intervals != nil {
+		clone.Intervals = make(map[string][]Interval, len(day.Intervals))
+		for k, v := range day.Intervals {
+			clone.Intervals[k] = append([]Interval(nil), v...)
 		}
-		if d == "weekdays" && isWeekday() {
-			return true
+	}
+	return clone
+}
+
+// getDayMinutes is the scheduled working time on date: a date override, else
+// the weekly schedule, else day_hours. An empty date gives the standard day.
+func getDayMinutes(config Config, date string) int {
+	if date != "" {
+		if hours, ok := config.ScheduleOverrides[date]; ok {
+			return hoursToMinutes(hours)
+		}
+		if t, err := time.Parse("2006-01-02", date); err == nil {
+			return scheduleMinutes(config, weekdayKey(t.Weekday()))
 		}
 	}
-	return false
+	if config.DayHours <= 0 {
+		return defaultDayHours * 60
+	}
+	return hoursToMinutes(config.DayHours)
+}
+
+// percentBase is the length 100% stands for on date. Days with nothing
+// scheduled are measured against a standard day so overtime still shows.
+func percentBase(config Config, date string) int {
+	if minutes := getDayMinutes(config, date); minutes > 0 {
+		return minutes
+	}
+	return getDayMinutes(config, "")
+}
+
+func weekdayKey(day time.Weekday) string {
+	return strings.ToLower(day.String()[:3])
+}
+
+func getExcludedMinutes(day DayData) int {
+	var total int
+	for _, minutes := range day.ExcludedMeetings {
+		total += minutes
+	}
+	return total
+}
+
+// getAvailableMinutes is the time left to track after meetings. Nothing is
+// available on leave days.
+func getAvailableMinutes(day DayData, config Config) int {
+	if isNonWorking(day) {
+		return 0
+	}
+	return getDayMinutes(config, day.Date) - getExcludedMinutes(day)
+}
+
+// isNonWorking reports whether day is a holiday or leave day
+func isNonWorking(day DayData) bool {
+	return day.Leave != ""
 }
 
-func getAvailablePercent(day DayData) float64 {
-	return 100.0 - day.ExcludedPercent
+// isWorkingDay reports whether any tracking is expected on day: it is not
+// leave and has hours scheduled
+func isWorkingDay(day DayData, config Config) bool {
+	return !isNonWorking(day) && getDayMinutes(config, day.Date) > 0
 }
 
-func getTotalTracked(day DayData) float64 {
-	var total float64
-	for _, pct := range day.Projects {
-		total += pct
+func getTotalTracked(day DayData) int {
+	var total int
+	for _, minutes := range day.Projects {
+		total += minutes
 	}
 	return total
 }
 
+// toPercent expresses minutes as a percentage of the working day on date (the
+// standard day if date is empty); percentages are only ever derived for
+// display and export, never stored
+func toPercent(minutes int, config Config, date string) float64 {
+	return float64(minutes) / float64(percentBase(config, date)) * 100.0
+}
+
+func getAvailablePercent(day DayData, config Config) float64 {
+	return toPercent(getAvailableMinutes(day, config), config, day.Date)
+}
+
+func getTrackedPercent(day DayData, config Config) float64 {
+	return toPercent(getTotalTracked(day), config, day.Date)
+}
+
+// setProjectTime records minutes against project on date, replacing any
+// previous entry for it (its time ranges stay if they still fit). Commands
+// that log a number of hours go through here; time ranges go through
+// addProjectIntervals.
+func setProjectTime(store Store, config Config, date, project string, minutes int, note string) (DayData, error) {
+	day, err := getDateData(store, config, date)
+	if err != nil {
+		return DayData{}, err
+	}
+	setProjectMinutes(&day, project, minutes)
+	setProjectNote(&day, project, note, false)
+	if err := store.SaveDay(day); err != nil {
+		return DayData{}, err
+	}
+	return day, nil
+}
+
+func warnOverAllocated(day DayData, config Config) {
+	total := getTotalTracked(day)
+	available := getAvailableMinutes(day, config)
+	if total > available {
+		fmt.Printf("⚠️  Warning: Over-allocated by %.1f%%!\n", toPercent(total-available, config, day.Date))
+	}
+}
+
 func resolveProject(name string, config Config) string {
 	if fullName, ok := config.Aliases[strings.ToLower(name)]; ok {
 		return fullName
@@ -169,7 +322,24 @@ func resolveProject(name string, config Config) string {
 	return name
 }
 
-func hoursToPercent(hours float64) float64 {
-	// Assuming an 8-hour workday
-	return (hours / 8.0) * 100.0
+func hoursToMinutes(hours float64) int {
+	return int(math.Round(hours * 60))
+}
+
+// formatDuration renders minutes as e.g. "2h30m", "45m" or "3h"
+func formatDuration(minutes int) string {
+	sign := ""
+	if minutes < 0 {
+		sign = "-"
+		minutes = -minutes
+	}
+	h, m := minutes/60, minutes%60
+	switch {
+	case h == 0:
+		return fmt.Sprintf("%s%dm", sign, m)
+	case m == 0:
+		return fmt.Sprintf("%s%dh", sign, h)
+	default:
+		return fmt.Sprintf("%s%dh%02dm", sign, h, m)
+	}
 }
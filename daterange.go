@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dayRange is an inclusive span of dates ("YYYY-MM-DD"). An empty From or To
+// leaves that end open.
+type dayRange struct {
+	From, To string
+	Label    string // e.g. "week 2025-W49", "December 2025"
+}
+
+// rangeFlags are the flags parseRangeArgs reads, for completion
+var rangeFlags = []string{"--from", "--to", "--week", "--month"}
+
+// rangeKeywords select common ranges without giving dates
+var rangeKeywords = []string{"this-week", "last-week", "this-month", "last-month"}
+
+// weekRange is the Monday to Sunday week containing t
+func weekRange(t time.Time) dayRange {
+	weekday := int(t.Weekday())
+	if weekday == 0 {
+		weekday = 7
+	}
+	monday := t.AddDate(0, 0, -(weekday - 1))
+	year, week := monday.ISOWeek()
+	return dayRange{
+		From:  monday.Format("2006-01-02"),
+		To:    monday.AddDate(0, 0, 6).Format("2006-01-02"),
+		Label: fmt.Sprintf("week %d-W%02d", year, week),
+	}
+}
+
+func thisWeek() dayRange {
+	return weekRange(time.Now())
+}
+
+func monthRange(year int, month time.Month) dayRange {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.Local)
+	return dayRange{
+		From:  first.Format("2006-01-02"),
+		To:    first.AddDate(0, 1, -1).Format("2006-01-02"),
+		Label: first.Format("January 2006"),
+	}
+}
+
+// parseISOWeek reads "2025-W49" as that ISO week's Monday to Sunday
+func parseISOWeek(s string) (dayRange, error) {
+	yearStr, weekStr, ok := strings.Cut(strings.ToUpper(s), "-W")
+	year, err1 := strconv.Atoi(yearStr)
+	week, err2 := strconv.Atoi(weekStr)
+	if !ok || err1 != nil || err2 != nil || week < 1 || week > 53 {
+		return dayRange{}, fmt.Errorf("invalid week %q (use YYYY-Www, e.g. 2025-W49)", s)
+	}
+	// 4 January is always in week 1
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.Local)
+	r := weekRange(jan4.AddDate(0, 0, (week-1)*7))
+	if y, w := mustParseDay(r.From).ISOWeek(); y != year || w != week {
+		return dayRange{}, fmt.Errorf("%d has no week %d", year, week)
+	}
+	return r, nil
+}
+
+// parseMonth reads "2025-12" as that calendar month
+func parseMonth(s string) (dayRange, error) {
+	t, err := time.Parse("2006-01", s)
+	if err != nil {
+		return dayRange{}, fmt.Errorf("invalid month %q (use YYYY-MM, e.g. 2025-12)", s)
+	}
+	return monthRange(t.Year(), t.Month()), nil
+}
+
+func mustParseDay(date string) time.Time {
+	t, _ := time.Parse("2006-01-02", date)
+	return t
+}
+
+// parseRangeArgs pulls a date range out of args: --from/--to, --week
+// YYYY-Www, --month YYYY-MM or one of rangeKeywords. It returns nil when
+// none was given, so each command can pick its own default.
+func parseRangeArgs(args []string) (*dayRange, []string, error) {
+	var r *dayRange
+	var from, to string
+	remainingArgs := []string{}
+
+	set := func(next dayRange) error {
+		if r != nil || from != "" || to != "" {
+			return fmt.Errorf("give only one date range")
+		}
+		r = &next
+		return nil
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch arg {
+		case "--from", "--to", "--week", "--month":
+			if i+1 >= len(args) {
+				return nil, nil, fmt.Errorf("%s flag requires a value", arg)
+			}
+			value := args[i+1]
+			i++
+			var err error
+			switch arg {
+			case "--from", "--to":
+				if r != nil {
+					return nil, nil, fmt.Errorf("give only one date range")
+				}
+				var date string
+				if date, err = parseDate(value); err == nil {
+					if arg == "--from" {
+						from = date
+					} else {
+						to = date
+					}
+				}
+			case "--week":
+				var week dayRange
+				if week, err = parseISOWeek(value); err == nil {
+					err = set(week)
+				}
+			case "--month":
+				var month dayRange
+				if month, err = parseMonth(value); err == nil {
+					err = set(month)
+				}
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+		case "this-week", "last-week", "this-month", "last-month":
+			now := time.Now()
+			var next dayRange
+			switch arg {
+			case "this-week":
+				next = thisWeek()
+			case "last-week":
+				next = weekRange(now.AddDate(0, 0, -7))
+			case "this-month":
+				next = monthRange(now.Year(), now.Month())
+			case "last-month":
+				first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local).AddDate(0, -1, 0)
+				next = monthRange(first.Year(), first.Month())
+			}
+			if err := set(next); err != nil {
+				return nil, nil, err
+			}
+		default:
+			remainingArgs = append(remainingArgs, arg)
+		}
+	}
+
+	if from != "" || to != "" {
+		if from != "" && to != "" && to < from {
+			return nil, nil, fmt.Errorf("%s is before %s", to, from)
+		}
+		r = &dayRange{From: from, To: to}
+		switch {
+		case from != "" && to != "":
+			r.Label = from + " to " + to
+		case from != "":
+			r.Label = "since " + from
+		default:
+			r.Label = "until " + to
+		}
+	}
+	return r, remainingArgs, nil
+}
+
+// dates lists every date in the range. An open start begins at the earliest
+// date in data and an open end stops at today.
+func (r dayRange) dates(data map[string]DayData) []string {
+	from, to := r.From, r.To
+	if from == "" {
+		keys := make([]string, 0, len(data))
+		for date := range data {
+			keys = append(keys, date)
+		}
+		sort.Strings(keys)
+		if len(keys) == 0 {
+			return nil
+		}
+		from = keys[0]
+	}
+	if to == "" {
+		to = today()
+	}
+
+	var dates []string
+	for d := mustParseDay(from); !d.After(mustParseDay(to)); d = d.AddDate(0, 0, 1) {
+		dates = append(dates, d.Format("2006-01-02"))
+	}
+	return dates
+}
+
+// isWeek reports whether the range is exactly one Monday to Sunday week
+func (r dayRange) isWeek() bool {
+	return r.From != "" && r.From == weekRange(mustParseDay(r.From)).From &&
+		r.To == weekRange(mustParseDay(r.From)).To
+}
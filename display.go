@@ -7,50 +7,66 @@ import (
 	"time"
 )
 
-func printStatus(day DayData) {
-	available := getAvailablePercent(day)
+func printStatus(day DayData, config Config) {
+	available := getAvailableMinutes(day, config)
 	tracked := getTotalTracked(day)
 	remaining := available - tracked
+	remainingPct := toPercent(remaining, config, day.Date)
 
 	fmt.Println()
 	fmt.Printf("%s📅 %s%s\n", ColorBold, day.Date, ColorReset)
 	fmt.Println(strings.Repeat("─", 45))
 
-	if day.ExcludedPercent > 0 {
-		fmt.Printf("🚫 Excluded (ceremonies): %s%.1f%%%s\n", ColorGray, day.ExcludedPercent, ColorReset)
-		if len(day.ExcludedMeetings) > 0 {
-			meetings := sortedKeys(day.ExcludedMeetings)
-			for _, name := range meetings {
-				fmt.Printf("   • %s: %s%.1f%%%s\n", name, ColorGray, day.ExcludedMeetings[name], ColorReset)
-			}
+	if isNonWorking(day) {
+		fmt.Printf("🌴 Non-working day: %s%s%s\n\n", ColorGray, day.Leave, ColorReset)
+	}
+
+	if excluded := getExcludedMinutes(day); excluded > 0 {
+		fmt.Printf("🚫 Excluded (ceremonies): %s%.1f%% (%s)%s\n", ColorGray,
+			toPercent(excluded, config, day.Date), formatDuration(excluded), ColorReset)
+		meetings := sortedKeys(day.ExcludedMeetings)
+		for _, name := range meetings {
+			minutes := day.ExcludedMeetings[name]
+			fmt.Printf("   • %s: %s%.1f%% (%s)%s\n", name, ColorGray,
+				toPercent(minutes, config, day.Date), formatDuration(minutes), ColorReset)
 		}
 		fmt.Println()
 	}
 
-	fmt.Printf("📊 Available to track: %s%.1f%%%s\n", ColorCyan, available, ColorReset)
-	fmt.Printf("✅ Tracked: %s%.1f%%%s\n", ColorBlue, tracked, ColorReset)
+	fmt.Printf("📊 Available to track: %s%.1f%% (%s)%s\n", ColorCyan,
+		toPercent(available, config, day.Date), formatDuration(available), ColorReset)
+	fmt.Printf("✅ Tracked: %s%.1f%% (%s)%s\n", ColorBlue,
+		toPercent(tracked, config, day.Date), formatDuration(tracked), ColorReset)
 
-	statusColor := getStatusColor(remaining)
-	fmt.Printf("⏳ Remaining: %s%.1f%%%s\n", statusColor, remaining, ColorReset)
+	statusColor := getStatusColor(remainingPct)
+	fmt.Printf("⏳ Remaining: %s%.1f%% (%s)%s\n", statusColor, remainingPct, formatDuration(remaining), ColorReset)
 	fmt.Println()
 
 	if len(day.Projects) > 0 {
 		fmt.Println("Projects:")
 		projects := sortedKeys(day.Projects)
 		for _, name := range projects {
-			pct := day.Projects[name]
+			minutes := day.Projects[name]
+			pct := toPercent(minutes, config, day.Date)
 			bar := progressBar(pct, 20)
-			fmt.Printf("   %s %s%5.1f%%%s %s\n", bar, ColorBlue, pct, ColorReset, name)
+			fmt.Printf("   %s %s%5.1f%%%s %6s  %s\n", bar, ColorBlue, pct, ColorReset, formatDuration(minutes), name)
+			if note := day.Notes[name]; note != "" {
+				fmt.Printf("   %s%s└ %s%s\n", strings.Repeat(" ", 36), ColorGray, note, ColorReset)
+			}
 		}
 		fmt.Println()
 	}
 
+	printTimeline(day)
+
 	if remaining < 0 {
-		fmt.Printf("%s⚠️  Over-allocated by %.1f%%!%s\n\n", ColorRed, -remaining, ColorReset)
+		fmt.Printf("%s⚠️  Over-allocated by %.1f%% (%s)!%s\n\n", ColorRed, -remainingPct, formatDuration(-remaining), ColorReset)
+	} else if isNonWorking(day) {
+		// Nothing is expected on leave days
 	} else if remaining == 0 {
 		fmt.Printf("%s✨ Day fully allocated!%s\n", ColorGreen, ColorReset)
-	} else if remaining < 10 {
-		fmt.Printf("%s💡 Only %.1f%% remaining - almost done!%s\n", ColorYellow, remaining, ColorReset)
+	} else if remainingPct < 10 {
+		fmt.Printf("%s💡 Only %.1f%% remaining - almost done!%s\n", ColorYellow, remainingPct, ColorReset)
 	}
 }
 
@@ -65,7 +81,7 @@ func progressBar(pct float64, width int) string {
 	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", width-filled) + "]"
 }
 
-func sortedKeys(m map[string]float64) []string {
+func sortedKeys[V any](m map[string]V) []string {
 	keys := make([]string, 0, len(m))
 	for k := range m {
 		keys = append(keys, k)
@@ -104,23 +120,94 @@ func printWeekExport(data map[string]DayData, config Config) {
 		fmt.Print(displayDate)
 
 		day, exists := data[dateStr]
-		var total float64
+		var total int
 
 		for _, p := range config.Projects {
-			pct := 0.0
+			minutes := 0
 			if exists {
-				if val, ok := day.Projects[p]; ok {
-					pct = val
-				}
+				minutes = day.Projects[p]
 			}
-			total += pct
-			if pct == 0 {
+			total += minutes
+			if minutes == 0 {
 				fmt.Print(",")
 			} else {
-				fmt.Printf(",%.1f%%", pct)
+				fmt.Printf(",%.1f%%", toPercent(minutes, config, dateStr))
 			}
 		}
 
 		fmt.Println()
 	}
 }
+
+func printSummary(day DayData, config Config) {
+	available := getAvailablePercent(day, config)
+	tracked := getTrackedPercent(day, config)
+	remaining := available - tracked
+
+	status := "✓"
+	if remaining < 0 {
+		status = "⚠️"
+	} else if remaining > 20 {
+		status = "⏳"
+	}
+
+	fmt.Printf("%s %s: %.1f%% tracked, %.1f%% remaining", status, day.Date, tracked, remaining)
+	if len(day.Projects) > 0 {
+		fmt.Print(" (")
+		i := 0
+		for name, minutes := range day.Projects {
+			if i > 0 {
+				fmt.Print(", ")
+			}
+			fmt.Printf("%s:%.0f%%", name, toPercent(minutes, config, day.Date))
+			i++
+			if i >= 3 {
+				fmt.Printf(" +%d more", len(day.Projects)-3)
+				break
+			}
+		}
+		fmt.Print(")")
+	}
+	fmt.Println()
+}
+
+func printHistory(data map[string]DayData, config Config, days int) {
+	fmt.Println()
+	fmt.Println("📆 History")
+	fmt.Println(strings.Repeat("─", 60))
+
+	dates := make([]string, 0)
+	for date := range data {
+		dates = append(dates, date)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(dates)))
+
+	count := 0
+	for _, date := range dates {
+		if count >= days {
+			break
+		}
+		day := data[date]
+		available := getAvailablePercent(day, config)
+		tracked := getTrackedPercent(day, config)
+
+		fmt.Printf("\n%s  Available: %.1f%%  Tracked: %.1f%%\n", date, available, tracked)
+
+		if len(day.Projects) > 0 {
+			projects := sortedKeys(day.Projects)
+			for _, name := range projects {
+				minutes := day.Projects[name]
+				fmt.Printf("   • %s: %.1f%% (%s)\n", name, toPercent(minutes, config, date), formatDuration(minutes))
+				if note := day.Notes[name]; note != "" {
+					fmt.Printf("     %s%s%s\n", ColorGray, note, ColorReset)
+				}
+			}
+		}
+		count++
+	}
+
+	if count == 0 {
+		fmt.Println("No history found")
+	}
+	fmt.Println()
+}
@@ -1,11 +1,12 @@
 package main
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"os"
 	"sort"
-	"time"
+	"strings"
 )
 
 func exportToJSON(data map[string]DayData, filename string) error {
@@ -40,22 +41,16 @@ func getAllProjects(data map[string]DayData) []string {
 	return projects
 }
 
-// getWeekProjects gets all projects used in the current week
-func getWeekProjects(data map[string]DayData) []string {
-	now := time.Now()
-	weekday := int(now.Weekday())
-	if weekday == 0 {
-		weekday = 7
-	}
-	monday := now.AddDate(0, 0, -(weekday - 1))
-
+// getRangeProjects gets all projects used in the given dates, leaving out
+// archived ones
+func getRangeProjects(data map[string]DayData, config Config, dates []string) []string {
 	projectSet := make(map[string]bool)
-	for i := range 7 {
-		date := monday.AddDate(0, 0, i)
-		dateStr := date.Format("2006-01-02")
-		if day, exists := data[dateStr]; exists {
+	for _, date := range dates {
+		if day, exists := data[date]; exists {
 			for project := range day.Projects {
-				projectSet[project] = true
+				if !isArchived(project, config) {
+					projectSet[project] = true
+				}
 			}
 		}
 	}
@@ -68,112 +63,209 @@ func getWeekProjects(data map[string]DayData) []string {
 	return projects
 }
 
-func exportWeekToCSV(data map[string]DayData, config Config, filename string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
-	}
-	defer file.Close()
+// csvColumn is a column of a CSV export: a single project, or the subtotal of
+// a group of projects
+type csvColumn struct {
+	Header   string
+	Projects []string
+}
 
-	now := time.Now()
-	weekday := int(now.Weekday())
-	if weekday == 0 {
-		weekday = 7
-	}
-	monday := now.AddDate(0, 0, -(weekday - 1))
+// subtotalPrefix marks group subtotal columns so import can skip them
+const subtotalPrefix = "Subtotal: "
 
-	// Auto-discover projects from this week's data (alphabetical)
-	projects := getWeekProjects(data)
+// csvColumns lays out the project columns. When grouping, projects are
+// arranged by group with a subtotal column after each group.
+func csvColumns(projects []string, config Config, groupBy string) []csvColumn {
+	if groupBy == "" {
+		columns := make([]csvColumn, 0, len(projects))
+		for _, p := range projects {
+			columns = append(columns, csvColumn{Header: p, Projects: []string{p}})
+		}
+		return columns
+	}
 
-	// Header
-	file.WriteString("Date")
+	byGroup := make(map[string][]string)
 	for _, p := range projects {
-		file.WriteString("," + p)
+		group := primaryGroup(p, config, groupBy)
+		byGroup[group] = append(byGroup[group], p)
 	}
-	file.WriteString(",Total Time Spent\n")
+	groups := sortedKeys(byGroup)
+	// Ungrouped projects, e.g. "(no client)", go last
+	sort.SliceStable(groups, func(i, j int) bool {
+		return !strings.HasPrefix(groups[i], "(") && strings.HasPrefix(groups[j], "(")
+	})
 
-	// Each day
-	for i := range 7 {
-		date := monday.AddDate(0, 0, i)
-		dateStr := date.Format("2006-01-02")
-		displayDate := date.Format("2-Jan")
+	var columns []csvColumn
+	for _, group := range groups {
+		for _, p := range byGroup[group] {
+			columns = append(columns, csvColumn{Header: p, Projects: []string{p}})
+		}
+		columns = append(columns, csvColumn{Header: subtotalPrefix + group, Projects: byGroup[group]})
+	}
+	return columns
+}
 
-		file.WriteString(displayDate)
+func (c csvColumn) minutes(day DayData) int {
+	var total int
+	for _, p := range c.Projects {
+		total += day.Projects[p]
+	}
+	return total
+}
 
-		day, exists := data[dateStr]
-		var total float64
+// CSV column kinds for Config.CSVColumns. "projects" stands for all the
+// project (and subtotal) columns together.
+const (
+	csvDate     = "date"
+	csvProjects = "projects"
+	csvTotal    = "total"
+	csvNotes    = "notes"
+)
 
-		for _, p := range projects {
-			pct := 0.0
-			if exists {
-				if val, ok := day.Projects[p]; ok {
-					pct = val
-				}
-			}
-			total += pct
-			if pct == 0 {
-				file.WriteString(",")
-			} else {
-				file.WriteString(fmt.Sprintf(",%.1f%%", pct))
-			}
-		}
+var csvColumnKinds = []string{csvDate, csvProjects, csvTotal, csvNotes}
 
-		file.WriteString("\n")
+// csvDateFormat is ISO so exports import back into the right year
+const csvDateFormat = "2006-01-02"
+
+// csvLayout is the column order for an export: the configured order, or
+// date, projects, total and (when withNotes) notes
+func csvLayout(config Config, withNotes bool) []string {
+	if len(config.CSVColumns) > 0 {
+		return config.CSVColumns
+	}
+	if withNotes {
+		return csvColumnKinds
 	}
+	return csvColumnKinds[:3]
+}
 
-	fmt.Printf("Exported current week to %s\n", filename)
-	return nil
+// parseCSVLayout checks a column order such as "date,total,projects". Date
+// and projects are required; each kind may appear once.
+func parseCSVLayout(s string) ([]string, error) {
+	var layout []string
+	for _, kind := range strings.Split(s, ",") {
+		kind = strings.ToLower(strings.TrimSpace(kind))
+		if !containsName(csvColumnKinds, kind) {
+			return nil, fmt.Errorf("unknown column %q (use %s)", kind, strings.Join(csvColumnKinds, ", "))
+		}
+		if containsName(layout, kind) {
+			return nil, fmt.Errorf("column %q given twice", kind)
+		}
+		layout = append(layout, kind)
+	}
+	if !containsName(layout, csvDate) || !containsName(layout, csvProjects) {
+		return nil, fmt.Errorf("columns must include date and projects")
+	}
+	return layout, nil
 }
 
-func exportAllToCSV(data map[string]DayData, config Config, filename string) error {
+// writeCSV writes a row per date in the given layout, with cells formatted
+// by the timesheet template
+func writeCSV(filename string, data map[string]DayData, config Config, dates []string, columns []csvColumn, layout []string) error {
+	format, err := newSheetFormat(config)
+	if err != nil {
+		return err
+	}
 	file, err := os.Create(filename)
 	if err != nil {
 		return fmt.Errorf("failed to create file: %w", err)
 	}
 	defer file.Close()
 
-	// Auto-discover all projects from all data (alphabetical)
-	projects := getAllProjects(data)
+	var header []string
+	var projectCols []int // Sheet columns of the projects, for a total formula
+	for _, kind := range layout {
+		switch kind {
+		case csvDate:
+			header = append(header, "Date")
+		case csvProjects:
+			for _, c := range columns {
+				if !strings.HasPrefix(c.Header, subtotalPrefix) {
+					projectCols = append(projectCols, len(header))
+				}
+				header = append(header, c.Header)
+			}
+		case csvTotal:
+			header = append(header, "Total Time Spent")
+		case csvNotes:
+			header = append(header, "Notes")
+		}
+	}
 
-	// Header
-	file.WriteString("Date")
-	for _, p := range projects {
-		file.WriteString("," + p)
+	w := csv.NewWriter(file)
+	w.Write(header)
+	for i, date := range dates {
+		day := data[date]
+		var row []string
+		for _, kind := range layout {
+			switch kind {
+			case csvDate:
+				row = append(row, format.formatDate(date))
+			case csvProjects:
+				for _, c := range columns {
+					row = append(row, format.formatValue(c.minutes(day), config, date))
+				}
+			case csvTotal:
+				if format.total != "" {
+					row = append(row, format.totalFormula(i+2, projectCols))
+					break
+				}
+				// The total of the project columns, leaving out subtotals
+				total := 0
+				for _, c := range columns {
+					if !strings.HasPrefix(c.Header, subtotalPrefix) {
+						total += c.minutes(day)
+					}
+				}
+				row = append(row, format.formatValue(total, config, date))
+			case csvNotes:
+				row = append(row, formatDayNotes(day))
+			}
+		}
+		w.Write(row)
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
 	}
-	file.WriteString(",Total Time Spent\n")
+	return nil
+}
 
-	// Get all dates sorted
-	dates := make([]string, 0, len(data))
-	for date := range data {
-		dates = append(dates, date)
+// timesheetProjects puts projects in timesheet template order, warning about
+// any the template doesn't have a column for
+func timesheetProjects(found []string, config Config) []string {
+	projects, extra := sheetProjects(found, config)
+	if len(extra) > 0 {
+		fmt.Printf("Note: %s not in the timesheet template; added as extra columns at the end\n", strings.Join(extra, ", "))
 	}
-	sort.Strings(dates)
+	return projects
+}
 
-	// Each day
-	for _, dateStr := range dates {
-		day := data[dateStr]
-		t, _ := time.Parse("2006-01-02", dateStr)
-		displayDate := t.Format("2-Jan")
+// exportRangeToCSV writes a row for every day in r, tracked or not
+func exportRangeToCSV(data map[string]DayData, config Config, r dayRange, filename, groupBy string) error {
+	dates := r.dates(data)
 
-		file.WriteString(displayDate)
+	// Auto-discover projects from the range's data (alphabetical, or in
+	// timesheet template order)
+	columns := csvColumns(timesheetProjects(getRangeProjects(data, config, dates), config), config, groupBy)
+	if err := writeCSV(filename, data, config, dates, columns, csvLayout(config, false)); err != nil {
+		return err
+	}
 
-		var total float64
-		for _, p := range projects {
-			pct := 0.0
-			if val, ok := day.Projects[p]; ok {
-				pct = val
-			}
-			total += pct
-			if pct == 0 {
-				file.WriteString(",")
-			} else {
-				file.WriteString(fmt.Sprintf(",%.1f%%", pct))
-			}
-		}
+	fmt.Printf("Exported %s to %s\n", r.Label, filename)
+	return nil
+}
 
-		file.WriteString("\n")
+// exportAllToCSV writes every day in data; label says what data covers.
+// importFile reads the file back to the same days.
+func exportAllToCSV(data map[string]DayData, config Config, label, filename, groupBy string) error {
+	// Auto-discover all projects from all data (alphabetical, or in
+	// timesheet template order)
+	columns := csvColumns(timesheetProjects(getAllProjects(data), config), config, groupBy)
+	if err := writeCSV(filename, data, config, sortedKeys(data), columns, csvLayout(config, true)); err != nil {
+		return err
 	}
 
-	fmt.Printf("Exported all data to %s\n", filename)
+	fmt.Printf("Exported %s to %s\n", label, filename)
 	return nil
 }
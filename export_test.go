@@ -0,0 +1,168 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func openTestStore(t *testing.T) Store {
+	t.Helper()
+	dir := t.TempDir()
+	store, err := newJSONStore(filepath.Join(dir, "data.json"), &backupPolicy{dir: dir, retention: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// TestCSVRoundTrip checks that importing what export all wrote gives back
+// the same minutes and notes, whatever the column order or timesheet template
+func TestCSVRoundTrip(t *testing.T) {
+	config := Config{
+		DayHours:          8,
+		ScheduleOverrides: map[string]float64{"2025-12-05": 4, "2025-12-06": 20},
+	}
+	days := []DayData{
+		{Date: "2025-12-01", Projects: map[string]int{"Acme, Inc.": 7, `Say "hi"`: 473},
+			Notes: map[string]string{"Acme, Inc.": "call; then notes, with commas"}},
+		{Date: "2025-12-02", Projects: map[string]int{"bugs": 1, "Main Project": 479}},
+		{Date: "2025-12-05", Projects: map[string]int{"bugs": 13, "Main Project": 227},
+			Notes: map[string]string{"bugs": "half day", "Main Project": "line one\nline two"}},
+		{Date: "2025-12-06", Projects: map[string]int{"Main Project": 1199, "bugs": 1}},
+	}
+
+	layouts := [][]string{nil, {csvNotes, csvTotal, csvProjects, csvDate}, nil, nil}
+	templates := []*TimesheetTemplate{nil, nil,
+		{DateFormat: "ddd dd/mm/yy", ValueFormat: valueHours, EmptyCell: "0", TotalFormula: "sum"},
+		{Columns: []string{"Main Project", "bugs"}, DateFormat: "d mmmm yyyy", ValueFormat: valueDecimal},
+	}
+	for i, layout := range layouts {
+		config.CSVColumns = layout
+		config.Timesheet = templates[i]
+
+		source := openTestStore(t)
+		if err := source.SaveDays(days); err != nil {
+			t.Fatal(err)
+		}
+		data, err := source.Range("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		file := filepath.Join(t.TempDir(), "export.csv")
+		if err := exportAllToCSV(data, config, "all data", file, ""); err != nil {
+			t.Fatal(err)
+		}
+
+		target := openTestStore(t)
+		if err := importFile(file, config, target); err != nil {
+			t.Fatal(err)
+		}
+		imported, err := target.Range("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(imported) != len(days) {
+			t.Fatalf("layout %v: imported %d days, want %d", layout, len(imported), len(days))
+		}
+		for _, want := range days {
+			got := imported[want.Date]
+			if !reflect.DeepEqual(got.Projects, want.Projects) {
+				t.Errorf("layout %v, %s: projects %v, want %v", layout, want.Date, got.Projects, want.Projects)
+			}
+			if len(want.Notes) > 0 && !reflect.DeepEqual(got.Notes, want.Notes) {
+				t.Errorf("layout %v, %s: notes %q, want %q", layout, want.Date, got.Notes, want.Notes)
+			}
+		}
+
+		// Exporting the imported days gives the same file
+		again := filepath.Join(t.TempDir(), "again.csv")
+		if err := exportAllToCSV(imported, config, "all data", again, ""); err != nil {
+			t.Fatal(err)
+		}
+		first, _ := os.ReadFile(file)
+		second, _ := os.ReadFile(again)
+		if string(first) != string(second) {
+			t.Errorf("layout %v: re-export differs:\n%s\nvs\n%s", layout, first, second)
+		}
+	}
+}
+
+func TestCSVTotalColumn(t *testing.T) {
+	config := Config{DayHours: 8}
+	data := map[string]DayData{
+		"2025-12-01": {Date: "2025-12-01", Projects: map[string]int{"a": 120, "b": 240}},
+	}
+	file := filepath.Join(t.TempDir(), "export.csv")
+	if err := exportAllToCSV(data, config, "all data", file, ""); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(file)
+	want := "Date,a,b,Total Time Spent,Notes\n2025-12-01,25.0%,50.0%,75.0%,\n"
+	if string(got) != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestXLSXRoundTrip checks that a workbook export, a sheet per week, imports
+// back to the same minutes and notes
+func TestXLSXRoundTrip(t *testing.T) {
+	config := Config{
+		DayHours:          8,
+		Projects:          []string{"Main Project", "bugs"},
+		ScheduleOverrides: map[string]float64{"2025-12-05": 4},
+	}
+	days := []DayData{
+		{Date: "2025-12-05", Projects: map[string]int{"bugs": 13, "Main Project": 227},
+			Notes: map[string]string{"bugs": "half day & <tags>"}},
+		{Date: "2025-12-08", Projects: map[string]int{"Acme, Inc.": 7, "Main Project": 473}},
+	}
+	data := make(map[string]DayData)
+	for _, d := range days {
+		data[d.Date] = d
+	}
+
+	for _, per := range []string{sheetsByWeek, sheetsByMonth} {
+		file := filepath.Join(t.TempDir(), "export.xlsx")
+		r := dayRange{From: "2025-12-01", To: "2025-12-14", Label: "test"}
+		if err := exportToXLSX(data, config, r, file, "", per); err != nil {
+			t.Fatal(err)
+		}
+
+		sheets, err := readXLSX(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := map[string]int{sheetsByWeek: 2, sheetsByMonth: 1}[per]; len(sheets) != want {
+			t.Fatalf("%s: %d sheets, want %d", per, len(sheets), want)
+		}
+		if got := sheets[0].Rows[0][1]; got != "Main Project" {
+			t.Errorf("%s: first project column %q, want Config.Projects order", per, got)
+		}
+
+		target := openTestStore(t)
+		if err := importFile(file, config, target); err != nil {
+			t.Fatal(err)
+		}
+		imported, err := target.Range("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(imported) != len(days) {
+			t.Fatalf("%s: imported %d days, want %d", per, len(imported), len(days))
+		}
+		for _, want := range days {
+			got := imported[want.Date]
+			if !reflect.DeepEqual(got.Projects, want.Projects) {
+				t.Errorf("%s, %s: projects %v, want %v", per, want.Date, got.Projects, want.Projects)
+			}
+			if len(want.Notes) > 0 && !reflect.DeepEqual(got.Notes, want.Notes) {
+				t.Errorf("%s, %s: notes %q, want %q", per, want.Date, got.Notes, want.Notes)
+			}
+		}
+	}
+}
@@ -1,8 +1,16 @@
 package main
 
 import (
+	"bufio"
 	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 // levenshteinDistance calculates the edit distance between two strings
@@ -60,101 +68,178 @@ func min(nums ...int) int {
 	return m
 }
 
-// fuzzyMatchProject finds the best matching project from the config
-func fuzzyMatchProject(input string, config Config) (string, []string) {
+// Match kinds in order of preference
+const (
+	matchEdit      = iota + 1 // Within a few typos
+	matchSubstring            // Appears anywhere in the name, or in an alias
+	matchAcronym              // Initials of the name's words, e.g. "cga" for "CT.GOV Automation"
+	matchPrefix               // Start of the name, or of an alias
+)
+
+// projectMatch is a candidate project with how well it matched
+type projectMatch struct {
+	name     string
+	kind     int
+	distance int     // Edit distance, for matchEdit
+	weight   float64 // How recently and often it's been used
+}
+
+// fuzzyMatchProject finds the best matching project among the configured
+// projects, alias targets and the tracked projects. Better kinds of match
+// win, then fewer typos, then projects with more weight (see projectWeights);
+// the name breaks any remaining tie so the result is always the same. The
+// other matches are returned as suggestions, best first.
+func fuzzyMatchProject(input string, config Config, projects []string, weights map[string]float64) (string, []string) {
 	// First, check exact alias match (existing behavior)
 	if fullName, ok := config.Aliases[strings.ToLower(input)]; ok {
 		return fullName, nil
 	}
 
+	candidates := append([]string(nil), config.Projects...)
+	for _, alias := range sortedKeys(config.Aliases) {
+		candidates = append(candidates, config.Aliases[alias])
+	}
+	candidates = append(candidates, projects...)
+
 	// Check exact match in projects
-	for _, proj := range config.Projects {
+	for _, proj := range candidates {
 		if strings.EqualFold(input, proj) {
 			return proj, nil
 		}
 	}
 
-	// If no exact match, try fuzzy matching
-	type match struct {
-		name     string
-		distance int
-	}
-
-	matches := make([]match, 0)
 	inputLower := strings.ToLower(input)
-
-	// Check against all projects
-	for _, proj := range config.Projects {
-		projLower := strings.ToLower(proj)
-
-		// Check if input is a substring
-		if strings.Contains(projLower, inputLower) {
-			matches = append(matches, match{proj, 0}) // High priority
-			continue
+	best := make(map[string]projectMatch)
+	consider := func(m projectMatch) {
+		if isArchived(m.name, config) {
+			return // Archived ones are only matched exactly
+		}
+		if prev, ok := best[m.name]; ok && !betterMatch(m, prev) {
+			return
 		}
+		m.weight = weights[m.name]
+		best[m.name] = m
+	}
 
-		// Calculate edit distance
-		dist := levenshteinDistance(input, proj)
-		// Only consider if distance is reasonable (less than half the length)
-		if dist <= len(proj)/2 && dist <= 3 {
-			matches = append(matches, match{proj, dist})
+	for _, proj := range candidates {
+		projLower := strings.ToLower(proj)
+		switch {
+		case strings.HasPrefix(projLower, inputLower):
+			consider(projectMatch{name: proj, kind: matchPrefix})
+		case len(inputLower) >= 2 && strings.HasPrefix(projectInitials(proj), inputLower):
+			consider(projectMatch{name: proj, kind: matchAcronym})
+		case strings.Contains(projLower, inputLower):
+			consider(projectMatch{name: proj, kind: matchSubstring})
+		default:
+			if dist, ok := typoDistance(input, proj); ok {
+				consider(projectMatch{name: proj, kind: matchEdit, distance: dist})
+			}
 		}
 	}
 
 	// Check against aliases
-	for alias, fullName := range config.Aliases {
-		if strings.Contains(alias, inputLower) {
-			// Check if not already in matches
-			found := false
-			for _, m := range matches {
-				if m.name == fullName {
-					found = true
-					break
-				}
-			}
-			if !found {
-				matches = append(matches, match{fullName, 0})
-			}
+	for _, alias := range sortedKeys(config.Aliases) {
+		switch {
+		case strings.HasPrefix(alias, inputLower):
+			consider(projectMatch{name: config.Aliases[alias], kind: matchPrefix})
+		case strings.Contains(alias, inputLower):
+			consider(projectMatch{name: config.Aliases[alias], kind: matchSubstring})
 		}
 	}
 
-	if len(matches) == 0 {
+	if len(best) == 0 {
 		return input, nil // No match found, return original
 	}
 
-	// Sort by distance (0 distance first - substring matches)
-	var best match
-	var suggestions []string
+	matches := make([]projectMatch, 0, len(best))
+	for _, m := range best {
+		matches = append(matches, m)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].kind != matches[j].kind || matches[i].distance != matches[j].distance {
+			return betterMatch(matches[i], matches[j])
+		}
+		if matches[i].weight != matches[j].weight {
+			return matches[i].weight > matches[j].weight
+		}
+		return matches[i].name < matches[j].name
+	})
 
-	for _, m := range matches {
-		if m.distance == 0 {
-			if best.name == "" {
-				best = m
-			} else {
-				suggestions = append(suggestions, m.name)
-			}
+	suggestions := make([]string, 0, len(matches)-1)
+	for _, m := range matches[1:] {
+		suggestions = append(suggestions, m.name)
+	}
+	return matches[0].name, suggestions
+}
+
+// betterMatch compares matches on kind, then number of typos
+func betterMatch(a, b projectMatch) bool {
+	if a.kind != b.kind {
+		return a.kind > b.kind
+	}
+	return a.distance < b.distance
+}
+
+// typoDistance reports how many typos separate input from name, comparing
+// against the whole name and each of its longer words so "automaton" finds
+// "Automation Tests"
+func typoDistance(input, name string) (int, bool) {
+	// Only consider if distance is reasonable (less than half the length)
+	best := levenshteinDistance(input, name)
+	ok := best <= len(name)/2 && best <= 3
+	for _, word := range strings.Fields(name) {
+		if len(word) < 4 {
+			continue
+		}
+		if dist := levenshteinDistance(input, word); dist <= len(word)/3 && dist <= 3 && (!ok || dist < best) {
+			best, ok = dist, true
 		}
 	}
+	return best, ok
+}
 
-	// If no substring matches, use closest edit distance
-	if best.name == "" {
-		best = matches[0]
-		for _, m := range matches[1:] {
-			if m.distance < best.distance {
-				suggestions = append(suggestions, best.name)
-				best = m
-			} else {
-				suggestions = append(suggestions, m.name)
+// projectInitials returns the lower-case first letter of each word in name,
+// splitting on spaces and punctuation
+func projectInitials(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var initials strings.Builder
+	for _, w := range words {
+		r, _ := utf8.DecodeRuneInString(w)
+		initials.WriteRune(r)
+	}
+	return initials.String()
+}
+
+// projectWeightDays is how far back projectWeights looks when resolving
+// project names
+const projectWeightDays = 90
+
+// projectWeights scores each project by how often it's been tracked, with
+// each day counting for less the longer ago it was: a day this week counts
+// 1, a day four weeks ago 1/5.
+func projectWeights(data map[string]DayData) map[string]float64 {
+	now, _ := time.Parse("2006-01-02", today())
+	weights := make(map[string]float64)
+	for date, day := range data {
+		t, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			continue
+		}
+		weeks := math.Max(0, now.Sub(t).Hours()/24/7)
+		for project, minutes := range day.Projects {
+			if minutes > 0 {
+				weights[project] += 1 / (1 + math.Floor(weeks))
 			}
 		}
 	}
-
-	return best.name, suggestions
+	return weights
 }
 
 // resolveProjectWithSuggestions attempts to resolve a project and shows suggestions if ambiguous
-func resolveProjectWithSuggestions(input string, config Config, interactive bool) string {
-	match, suggestions := fuzzyMatchProject(input, config)
+func resolveProjectWithSuggestions(input string, config Config, projects []string, weights map[string]float64, interactive bool) string {
+	match, suggestions := fuzzyMatchProject(input, config, projects, weights)
 
 	// If we found a perfect match (through alias or exact name), return it
 	if len(suggestions) == 0 && match != input {
@@ -175,3 +260,122 @@ func resolveProjectWithSuggestions(input string, config Config, interactive bool
 
 	return match
 }
+
+// getNewFlag pulls "--new" out of args. It lets strict mode create a project
+// that isn't known yet.
+func getNewFlag(args []string) (bool, []string) {
+	allowNew := false
+	remainingArgs := []string{}
+	for _, arg := range args {
+		if arg == "--new" {
+			allowNew = true
+		} else {
+			remainingArgs = append(remainingArgs, arg)
+		}
+	}
+	return allowNew, remainingArgs
+}
+
+// knownProjects lists every project in the config, alias targets and the
+// tracked projects
+func knownProjects(projects []string, config Config) []string {
+	var known []string
+	add := func(p string) {
+		if p != "" && !containsName(known, p) {
+			known = append(known, p)
+		}
+	}
+	for _, p := range config.Projects {
+		add(p)
+	}
+	for _, alias := range sortedKeys(config.Aliases) {
+		add(config.Aliases[alias])
+	}
+	for _, p := range projects {
+		add(p)
+	}
+	return known
+}
+
+// resolveProjectInput resolves a project typed by the user. Outside strict
+// mode it behaves like resolveProjectWithSuggestions. In strict mode only
+// known projects are accepted: a single partial match is used, several
+// candidates or a likely typo ask for a numbered choice on a terminal and
+// fail otherwise, and a new project needs allowNew (--new).
+func resolveProjectInput(input string, store Store, config Config, allowNew bool) (string, error) {
+	if fullName, ok := config.Aliases[strings.ToLower(input)]; ok {
+		return fullName, nil
+	}
+	projects, err := store.Projects()
+	if err != nil {
+		return "", err
+	}
+	// Recent use ranks the matches; older days would barely count anyway
+	recent, err := store.Range(time.Now().AddDate(0, 0, -projectWeightDays).Format("2006-01-02"), "")
+	if err != nil {
+		return "", err
+	}
+	weights := projectWeights(recent)
+	if !config.StrictProjects {
+		return resolveProjectWithSuggestions(input, config, projects, weights, true), nil
+	}
+
+	for _, p := range knownProjects(projects, config) {
+		if strings.EqualFold(p, input) {
+			return p, nil
+		}
+	}
+	if allowNew {
+		fmt.Printf("Creating new project: %s\n", input)
+		return input, nil
+	}
+
+	match, suggestions := fuzzyMatchProject(input, config, projects, weights)
+	var candidates []string
+	if match != input {
+		candidates = append(candidates, match)
+	}
+	for _, s := range suggestions {
+		if !containsName(candidates, s) {
+			candidates = append(candidates, s)
+		}
+	}
+
+	switch {
+	case len(candidates) == 0:
+		return "", fmt.Errorf("unknown project %q (strict mode); use --new to create it", input)
+	case len(candidates) == 1 && strings.Contains(strings.ToLower(candidates[0]), strings.ToLower(input)):
+		fmt.Println("Using:", candidates[0])
+		return candidates[0], nil
+	case !stdinIsTerminal() && len(candidates) == 1:
+		return "", fmt.Errorf("unknown project %q (strict mode); did you mean %s? Use --new to create it", input, candidates[0])
+	case !stdinIsTerminal():
+		return "", fmt.Errorf("%q is ambiguous (strict mode): could be %s", input, strings.Join(candidates, ", "))
+	}
+	return chooseProject(input, candidates)
+}
+
+// chooseProject asks the user to pick one of candidates by number
+func chooseProject(input string, candidates []string) (string, error) {
+	if len(candidates) == 1 {
+		fmt.Printf("\n'%s' isn't a known project. Did you mean:\n", input)
+	} else {
+		fmt.Printf("\n'%s' matches more than one project:\n", input)
+	}
+	for i, c := range candidates {
+		fmt.Printf("  %d. %s\n", i+1, c)
+	}
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Printf("Choose 1-%d (Enter to cancel): ", len(candidates))
+		line, err := reader.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if line == "" || err != nil {
+			return "", fmt.Errorf("no project chosen")
+		}
+		if n, err := strconv.Atoi(line); err == nil && n >= 1 && n <= len(candidates) {
+			return candidates[n-1], nil
+		}
+		fmt.Println("Invalid choice")
+	}
+}
@@ -6,88 +6,18 @@ import (
 	"strings"
 )
 
-func printHelp() {
-	fmt.Println(`
-timetrack - Track your day in hours
-
-Usage:
-  timetrack                        Show today's status
-  timetrack interactive            Interactive menu mode
-  timetrack add <project> <hours>  Add/update time to a project
-  timetrack fill <project>         Fill remaining time with project
-  timetrack edit <project> <hours> Update existing project time
-  timetrack copy <date>            Copy projects from another date to today
-  timetrack exclude <name> <hours> Exclude ceremony time (one-off)
-  timetrack rm <project>           Remove a project entry
-  timetrack rmex <name>            Remove an excluded meeting
-  timetrack undo                   Remove last added project
-  timetrack clear                  Clear today's data
-
-Viewing:
-  timetrack                        Show today's status
-  timetrack show [days]            Calendar view (default: 7 days)
-
-Date Flag (for add, fill, edit, rm, copy):
-  --date <date> or -d <date>   Work with a specific date
-
-  Supported formats (UK only):
-    DD-MM-YYYY (preferred): 05-12-2024
-    DD/MM/YYYY:             05/12/2024
-    YYYY-MM-DD (ISO):       2024-12-05
-
-  Examples:
-    timetrack add bugs 2 --date 05-12-2024
-    timetrack fill "Main Project" -d 2024-12-05
-    timetrack edit automation 3 --date 05/12/2024
-    timetrack rm bugs -d 05-12-2024
-    timetrack copy 08-12-2024            (copy to today)
-    timetrack copy 08-12-2024 -d 10-12-2024  (copy to specific date)
-
-Export & Import:
-  timetrack export csv [file]      Export week to CSV (auto-discovered projects)
-  timetrack export all [file]      Export all data to CSV
-  timetrack export json [file]     Export as JSON
-  timetrack import <csv-file>      Import data from CSV
-
-Projects & Aliases:
-  timetrack projects list          List all projects (auto-discovered from time)
-  timetrack alias <short> <full>   Create/update alias
-  timetrack alias rm <short>       Remove alias
-  timetrack alias list             List all aliases
-
-Config:
-  timetrack config                 Show current config
-  timetrack config edit            Open config file in editor
-  timetrack meeting add <name> <hours> <days>   Add recurring meeting
-  timetrack meeting rm <name>      Remove recurring meeting
-  timetrack reminder <times>       Set reminder times (e.g., "09:00,12:00,15:00")
-  timetrack url set <url>          Set online timesheet URL
-  timetrack url open               Open timesheet URL in browser
-  timetrack url                    Show current timesheet URL
-  timetrack url rm                 Clear timesheet URL
-
-Reminders:
-  timetrack start                  Start reminder service (foreground)
-  timetrack start-bg               Start reminder service (background)
-  timetrack stop                   Stop reminder service
-  timetrack status                 Check if reminder service is running
-  timetrack test-notify            Send a test notification
-
-Advanced Projects (rarely needed):
-  timetrack projects set "P1,P2"   Manually configure project list
-  timetrack projects parse "..."   Parse Excel header for projects
-
-Days: mon, tue, wed, thu, fri, sat, sun, daily, weekdays
-
-Note: Based on 8-hour workday. All input is in hours, converted to percentages internally.`)
-}
-
 func printConfig(config Config) {
 	fmt.Println()
 	fmt.Println("⚙️  Configuration")
 	fmt.Println(strings.Repeat("─", 45))
 	fmt.Printf("Config file: %s\n\n", getConfigPath())
 
+	fmt.Printf("Working day: %s\n", formatDuration(getDayMinutes(config, "")))
+	if len(config.Schedule) > 0 || len(config.ScheduleOverrides) > 0 {
+		printSchedule(config)
+	}
+	fmt.Println()
+
 	fmt.Println("Reminder times:")
 	if len(config.ReminderTimes) == 0 {
 		fmt.Println("   (none)")
@@ -102,13 +32,24 @@ func printConfig(config Config) {
 		fmt.Println("   (none)")
 	} else {
 		for _, m := range config.RecurringMeetings {
-			fmt.Printf("   • %s: %.1f%% on %s\n", m.Name, m.Percent, strings.Join(m.Days, ", "))
+			fmt.Printf("   • %s: %s on %s\n", m.Name, formatDuration(m.Minutes), strings.Join(m.Days, ", "))
 		}
 	}
 
 	fmt.Println("\nProjects:")
 	fmt.Println("   Auto-discovered from your time entries")
 	fmt.Println("   Use 'timetrack projects list' to see all tracked projects")
+	if config.StrictProjects {
+		fmt.Println("   Strict mode: unknown project names are rejected (use --new)")
+	}
+
+	if len(config.CSVColumns) > 0 {
+		fmt.Printf("\nCSV columns: %s\n", strings.Join(config.CSVColumns, ", "))
+	}
+	if config.Timesheet != nil {
+		fmt.Println()
+		printTimesheet(config)
+	}
 
 	fmt.Println("\nAliases:")
 	if len(config.Aliases) == 0 {
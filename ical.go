@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// icalEvent is the subset of a VEVENT that timetrack uses
+type icalEvent struct {
+	Summary string
+	Start   time.Time
+	End     time.Time // Exclusive; zero if the event had no DTEND
+	AllDay  bool
+	Props   map[string]string // Raw values of every property, by name
+
+	UID          string
+	RecurrenceID time.Time      // Set on an event that replaces one occurrence of a recurring event
+	ExDates      []time.Time    // Occurrences left out of the RRULE
+	Location     *time.Location // Zone DTSTART was given in; the RRULE repeats in its wall-clock time
+}
+
+// icalProperty is one unfolded content line, e.g. DTSTART;VALUE=DATE:20251225
+type icalProperty struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+func loadICS(filename string) ([]icalEvent, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	events, err := parseICS(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+	return events, nil
+}
+
+func parseICS(r io.Reader) ([]icalEvent, error) {
+	lines, err := unfoldICSLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []icalEvent
+	var current *icalEvent
+	for _, line := range lines {
+		prop := parseICSProperty(line)
+		switch {
+		case prop.Name == "BEGIN" && strings.EqualFold(prop.Value, "VEVENT"):
+			current = &icalEvent{Props: make(map[string]string)}
+		case prop.Name == "END" && strings.EqualFold(prop.Value, "VEVENT"):
+			if current == nil {
+				continue
+			}
+			if current.Start.IsZero() {
+				return nil, fmt.Errorf("event %q has no DTSTART", current.Summary)
+			}
+			if current.End.IsZero() && current.Props["DURATION"] != "" {
+				d, err := parseICSDuration(current.Props["DURATION"])
+				if err != nil {
+					return nil, fmt.Errorf("event %q: %w", current.Summary, err)
+				}
+				current.End = current.Start.Add(d)
+			}
+			events = append(events, *current)
+			current = nil
+		case current != nil:
+			current.Props[prop.Name] = prop.Value
+			switch prop.Name {
+			case "SUMMARY":
+				current.Summary = unescapeICSText(prop.Value)
+			case "DTSTART":
+				t, allDay, err := parseICSTime(prop)
+				if err != nil {
+					return nil, err
+				}
+				current.Start, current.AllDay = t, allDay
+				current.Location = icsLocation(prop)
+			case "DTEND":
+				t, _, err := parseICSTime(prop)
+				if err != nil {
+					return nil, err
+				}
+				current.End = t
+			case "UID":
+				current.UID = prop.Value
+			case "RECURRENCE-ID":
+				t, _, err := parseICSTime(prop)
+				if err != nil {
+					return nil, err
+				}
+				current.RecurrenceID = t
+			case "EXDATE":
+				// May repeat, and each may list several comma-separated times
+				for _, value := range strings.Split(prop.Value, ",") {
+					t, _, err := parseICSTime(icalProperty{Name: prop.Name, Params: prop.Params, Value: value})
+					if err != nil {
+						return nil, err
+					}
+					current.ExDates = append(current.ExDates, t)
+				}
+			}
+		}
+	}
+	return events, nil
+}
+
+// unfoldICSLines joins continuation lines (those starting with a space or tab)
+// onto the line before, as RFC 5545 requires
+func unfoldICSLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+func parseICSProperty(line string) icalProperty {
+	prop := icalProperty{Params: make(map[string]string)}
+	// The value starts at the first colon that isn't inside a quoted parameter
+	inQuotes := false
+	split := len(line)
+	for i, c := range line {
+		if c == '"' {
+			inQuotes = !inQuotes
+		} else if c == ':' && !inQuotes {
+			split = i
+			break
+		}
+	}
+	head := line[:split]
+	if split < len(line) {
+		prop.Value = line[split+1:]
+	}
+
+	parts := strings.Split(head, ";")
+	prop.Name = strings.ToUpper(parts[0])
+	for _, p := range parts[1:] {
+		if k, v, ok := strings.Cut(p, "="); ok {
+			prop.Params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return prop
+}
+
+// parseICSTime reads a DATE or DATE-TIME value. UTC times are converted to
+// local time; times with a TZID are interpreted in that zone if it is known,
+// otherwise as local time.
+func parseICSTime(prop icalProperty) (time.Time, bool, error) {
+	value := prop.Value
+	if prop.Params["VALUE"] == "DATE" || len(value) == 8 {
+		t, err := time.ParseInLocation("20060102", value, time.Local)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid date %s: %s", prop.Name, value)
+		}
+		return t, true, nil
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid time %s: %s", prop.Name, value)
+		}
+		return t.Local(), false, nil
+	}
+
+	t, err := time.ParseInLocation("20060102T150405", value, icsLocation(prop))
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid time %s: %s", prop.Name, value)
+	}
+	return t.Local(), false, nil
+}
+
+// icsLocation is the zone a time value is given in: UTC for times ending in
+// Z, its TZID if known, else local time (floating times and dates)
+func icsLocation(prop icalProperty) *time.Location {
+	if strings.HasSuffix(prop.Value, "Z") {
+		return time.UTC
+	}
+	if tzid := prop.Params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			return l
+		}
+	}
+	return time.Local
+}
+
+// parseICSDuration reads a DURATION value such as PT1H30M, P1D or P1W
+func parseICSDuration(value string) (time.Duration, error) {
+	s := strings.TrimPrefix(strings.TrimPrefix(value, "+"), "P")
+	if s == value || s == "" {
+		return 0, fmt.Errorf("invalid duration: %s", value)
+	}
+	var d time.Duration
+	inTime := false
+	number := 0
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			number = number*10 + int(c-'0')
+			continue
+		case c == 'T':
+			inTime = true
+		case c == 'W' && !inTime:
+			d += time.Duration(number) * 7 * 24 * time.Hour
+		case c == 'D' && !inTime:
+			d += time.Duration(number) * 24 * time.Hour
+		case c == 'H' && inTime:
+			d += time.Duration(number) * time.Hour
+		case c == 'M' && inTime:
+			d += time.Duration(number) * time.Minute
+		case c == 'S' && inTime:
+			d += time.Duration(number) * time.Second
+		default:
+			return 0, fmt.Errorf("invalid duration: %s", value)
+		}
+		number = 0
+	}
+	return d, nil
+}
+
+func unescapeICSText(s string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
+
+// eventDates lists the days an event covers. All-day events end the day
+// before DTEND; timed events cover each day they touch.
+func eventDates(event icalEvent) []string {
+	start := time.Date(event.Start.Year(), event.Start.Month(), event.Start.Day(), 0, 0, 0, 0, time.Local)
+	end := start
+	if !event.End.IsZero() {
+		last := event.End
+		if event.AllDay {
+			last = last.AddDate(0, 0, -1)
+		} else {
+			last = last.Add(-time.Nanosecond)
+		}
+		if last.After(start) {
+			end = time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, time.Local)
+		}
+	}
+
+	var dates []string
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		dates = append(dates, d.Format("2006-01-02"))
+	}
+	return dates
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// importICS books the timed events of a calendar export that fall in r to
+// the days they happen on, as excluded meetings or project time going by
+// Config.CalendarRules. Events no rule matches are excluded meetings under
+// their titles. All-day events are left to leave import.
+func importICS(filename string, r dayRange, config Config, store Store, dryRun bool) error {
+	if r.From == "" {
+		return fmt.Errorf("calendar imports need a range with a start, e.g. --from 2025-12-01")
+	}
+	if r.To == "" {
+		r.To = today()
+	}
+	events, err := loadICS(filename)
+	if err != nil {
+		return err
+	}
+	from, _ := time.ParseInLocation("2006-01-02", r.From, time.Local)
+	to, _ := time.ParseInLocation("2006-01-02", r.To, time.Local)
+
+	// Occurrences of a recurring event that another event moves or cancels
+	replaced := make(map[string]bool)
+	for _, event := range events {
+		if !event.RecurrenceID.IsZero() {
+			replaced[occurrenceKey(event.UID, event.RecurrenceID)] = true
+		}
+	}
+
+	meetings := make(map[string]map[string]int) // Date → meeting → minutes
+	projects := make(map[string]map[string]int) // Date → project → minutes
+	unmatched := make(map[string]int)
+	booked := 0
+	for _, event := range events {
+		if event.AllDay || !event.End.After(event.Start) || strings.EqualFold(event.Props["STATUS"], "CANCELLED") {
+			continue
+		}
+		starts, err := occurrences(event, from, to)
+		if err != nil {
+			fmt.Printf("Warning: Skipping %q - %v\n", event.Summary, err)
+			continue
+		}
+		rule, matched := matchCalendarRule(config.CalendarRules, event.Summary)
+		length := event.End.Sub(event.Start)
+		for _, start := range starts {
+			if event.RecurrenceID.IsZero() && replaced[occurrenceKey(event.UID, start)] {
+				continue
+			}
+			// Events no rule covers are still meetings, excluded
+			// under their own titles
+			if !matched {
+				unmatched[event.Summary]++
+			}
+			if rule.Skip {
+				continue
+			}
+			target, name := meetings, rule.Meeting
+			if rule.Project != "" {
+				target, name = projects, resolveProject(rule.Project, config)
+			} else if name == "" {
+				name = event.Summary
+			}
+			booked++
+
+			// Events running past midnight count on each day they cover
+			end := start.Add(length)
+			for s := start; s.Before(end); {
+				next := midnight(s).AddDate(0, 0, 1)
+				if next.After(end) {
+					next = end
+				}
+				if date := s.Format("2006-01-02"); date >= r.From && date <= r.To {
+					if target[date] == nil {
+						target[date] = make(map[string]int)
+					}
+					target[date][name] += int(math.Round(next.Sub(s).Minutes()))
+				}
+				s = next
+			}
+		}
+	}
+
+	hasTime := make(map[string]bool)
+	for date := range meetings {
+		hasTime[date] = true
+	}
+	for date := range projects {
+		hasTime[date] = true
+	}
+	dates := sortedKeys(hasTime)
+
+	fmt.Printf("Calendar events for %s:\n", r.Label)
+	var days []DayData
+	for _, date := range dates {
+		day, err := getDateData(store, config, date)
+		if err != nil {
+			return err
+		}
+		if isNonWorking(day) {
+			fmt.Printf("   • %s  skipped (%s)\n", date, day.Leave)
+			continue
+		}
+
+		// Meetings are set rather than added to, so importing again
+		// changes nothing; project time already tracked is kept
+		var entries []string
+		for _, name := range sortedKeys(meetings[date]) {
+			minutes := meetings[date][name]
+			if day.ExcludedMeetings == nil {
+				day.ExcludedMeetings = make(map[string]int)
+			}
+			day.ExcludedMeetings[name] = minutes
+			entries = append(entries, fmt.Sprintf("%s %s (excluded)", name, formatDuration(minutes)))
+		}
+		for _, project := range sortedKeys(projects[date]) {
+			minutes := projects[date][project]
+			if tracked := day.Projects[project]; tracked > 0 && tracked != minutes {
+				entries = append(entries, fmt.Sprintf("%s %s (kept the %s tracked)", project, formatDuration(minutes), formatDuration(tracked)))
+				continue
+			}
+			setProjectMinutes(&day, project, minutes)
+			entries = append(entries, fmt.Sprintf("%s %s", project, formatDuration(minutes)))
+		}
+		fmt.Printf("   • %s  %s\n", date, strings.Join(entries, ", "))
+		days = append(days, day)
+	}
+	if len(dates) == 0 {
+		fmt.Println("   No matching events")
+	}
+
+	if len(unmatched) > 0 {
+		fmt.Println("No rule for (excluded under their titles):")
+		for _, title := range sortedKeys(unmatched) {
+			fmt.Printf("   • %s (%d)\n", title, unmatched[title])
+		}
+		fmt.Println("Add one with: timetrack meeting rule <pattern> exclude|project <name>|skip")
+	}
+
+	if dryRun {
+		fmt.Println("Dry run: nothing saved")
+		return nil
+	}
+	if len(days) > 0 {
+		if err := store.SaveDays(days); err != nil {
+			return fmt.Errorf("failed to save imported data: %w", err)
+		}
+	}
+	fmt.Printf("Imported %d event(s) into %d day(s) from %s\n", booked, len(days), filename)
+	return nil
+}
+
+func occurrenceKey(uid string, start time.Time) string {
+	return uid + "@" + start.UTC().Format(time.RFC3339)
+}
+
+// matchCalendarRule finds the first rule whose pattern matches title
+func matchCalendarRule(rules []CalendarRule, title string) (CalendarRule, bool) {
+	for _, rule := range rules {
+		if matchTitle(rule.Pattern, title) {
+			return rule, true
+		}
+	}
+	return CalendarRule{}, false
+}
+
+// matchTitle reports whether title contains pattern, ignoring case, with *
+// in the pattern matching any text
+func matchTitle(pattern, title string) bool {
+	title = strings.ToLower(title)
+	for _, part := range strings.Split(strings.ToLower(pattern), "*") {
+		i := strings.Index(title, part)
+		if i < 0 {
+			return false
+		}
+		title = title[i+len(part):]
+	}
+	return true
+}
+
+// handleCalendarRule adds, replaces or removes a rule for import ics
+func handleCalendarRule(config Config, args []string) error {
+	if len(args) >= 2 && args[0] == "rm" {
+		pattern := strings.Join(args[1:], " ")
+		for i, rule := range config.CalendarRules {
+			if strings.EqualFold(rule.Pattern, pattern) {
+				config.CalendarRules = append(config.CalendarRules[:i], config.CalendarRules[i+1:]...)
+				if err := saveConfig(config); err != nil {
+					return err
+				}
+				fmt.Printf("Removed calendar rule: %s\n", rule.Pattern)
+				return nil
+			}
+		}
+		fmt.Printf("Calendar rule '%s' not found\n", pattern)
+		return nil
+	}
+	if len(args) < 2 {
+		printCalendarRuleUsage()
+		return nil
+	}
+
+	rule := CalendarRule{Pattern: args[0]}
+	name := strings.Join(args[2:], " ")
+	switch args[1] {
+	case "exclude":
+		rule.Meeting = name
+	case "project":
+		if name == "" {
+			printCalendarRuleUsage()
+			return nil
+		}
+		rule.Project = resolveProject(name, config)
+	case "skip":
+		rule.Skip = true
+	default:
+		printCalendarRuleUsage()
+		return nil
+	}
+
+	// A rule for the same pattern is replaced where it stands, keeping
+	// the order rules are tried in
+	found := false
+	for i, r := range config.CalendarRules {
+		if strings.EqualFold(r.Pattern, rule.Pattern) {
+			config.CalendarRules[i] = rule
+			found = true
+			break
+		}
+	}
+	if !found {
+		config.CalendarRules = append(config.CalendarRules, rule)
+	}
+	if err := saveConfig(config); err != nil {
+		return err
+	}
+	fmt.Printf("Calendar rule: %s\n", describeCalendarRule(rule))
+	return nil
+}
+
+func printCalendarRuleUsage() {
+	fmt.Println("Usage: timetrack meeting rule <pattern> exclude [name]")
+	fmt.Println("       timetrack meeting rule <pattern> project <project>")
+	fmt.Println("       timetrack meeting rule <pattern> skip")
+	fmt.Println("       timetrack meeting rule rm <pattern>")
+	fmt.Println("Patterns match anywhere in the event title, ignoring case; * matches any text")
+}
+
+func printCalendarRules(config Config) {
+	if len(config.CalendarRules) == 0 {
+		fmt.Println("No calendar rules; every event is excluded under its title")
+		fmt.Println("Add one with: timetrack meeting rule <pattern> exclude|project <name>|skip")
+		return
+	}
+	fmt.Println("Calendar rules (first match wins):")
+	for _, rule := range config.CalendarRules {
+		fmt.Printf("   • %s\n", describeCalendarRule(rule))
+	}
+}
+
+func describeCalendarRule(rule CalendarRule) string {
+	switch {
+	case rule.Skip:
+		return fmt.Sprintf("%q → skipped", rule.Pattern)
+	case rule.Project != "":
+		return fmt.Sprintf("%q → project %s", rule.Pattern, rule.Project)
+	case rule.Meeting != "":
+		return fmt.Sprintf("%q → excluded as %s", rule.Pattern, rule.Meeting)
+	}
+	return fmt.Sprintf("%q → excluded under the event title", rule.Pattern)
+}
@@ -4,57 +4,129 @@ import (
 	"encoding/csv"
 	"fmt"
 	"os"
-	"strconv"
+	"path/filepath"
 	"strings"
-	"time"
 )
 
-func importFromCSV(filename string, config Config) error {
-	file, err := os.Open(filename)
-	if err != nil {
-		return fmt.Errorf("failed to open file: %w", err)
+// sheet is a table read from an import file: a CSV file, or one sheet of a
+// workbook
+type sheet struct {
+	Name string // Empty for CSV
+	Rows [][]string
+}
+
+// importFile reads a CSV file or, going by the extension, an XLSX workbook
+// into the store. Every sheet of a workbook is read like a CSV file.
+func importFile(filename string, config Config, store Store) error {
+	var sheets []sheet
+	if strings.EqualFold(filepath.Ext(filename), ".xlsx") {
+		var err error
+		if sheets, err = readXLSX(filename); err != nil {
+			return err
+		}
+	} else {
+		file, err := os.Open(filename)
+		if err != nil {
+			return fmt.Errorf("failed to open file: %w", err)
+		}
+		defer file.Close()
+
+		records, err := csv.NewReader(file).ReadAll()
+		if err != nil {
+			return fmt.Errorf("failed to read CSV: %w", err)
+		}
+		sheets = []sheet{{Rows: records}}
 	}
-	defer file.Close()
 
-	reader := csv.NewReader(file)
-	records, err := reader.ReadAll()
+	// Dates and values are read in the timesheet template's format first
+	format, err := newSheetFormat(config)
 	if err != nil {
-		return fmt.Errorf("failed to read CSV: %w", err)
+		return err
 	}
 
-	if len(records) < 2 {
-		return fmt.Errorf("CSV file must have at least a header and one data row")
+	imp := &importer{config: config, store: store, format: format, imported: make(map[string]DayData)}
+	read := 0
+	for _, s := range sheets {
+		// First row is header
+		if len(s.Rows) < 2 || len(s.Rows[0]) < 2 {
+			continue
+		}
+		if err := imp.readSheet(s); err != nil {
+			return err
+		}
+		read++
 	}
-
-	// First row is header
-	headers := records[0]
-	if len(headers) < 2 {
-		return fmt.Errorf("CSV must have at least Date and one project column")
+	if read == 0 {
+		return fmt.Errorf("file must have a header with Date and at least one project column, and one data row")
 	}
 
-	// Projects are all columns except first (Date) and last (Total)
-	projectCols := headers[1:]
-	if strings.Contains(strings.ToLower(headers[len(headers)-1]), "total") {
-		projectCols = headers[1 : len(headers)-1]
+	days := make([]DayData, 0, len(imp.order))
+	for _, dayKey := range imp.order {
+		days = append(days, imp.imported[dayKey])
+	}
+	if err := store.SaveDays(days); err != nil {
+		return fmt.Errorf("failed to save imported data: %w", err)
 	}
+	fmt.Printf("Successfully imported %d days from %s\n", len(imp.imported), filename)
+	return nil
+}
 
-	data := loadData()
-	imported := 0
+// importer gathers the days read from an import file until they're saved
+type importer struct {
+	config   Config
+	store    Store
+	format   sheetFormat
+	imported map[string]DayData
+	order    []string
+}
+
+func (imp *importer) readSheet(s sheet) error {
+	config, format := imp.config, imp.format
+	headers := s.Rows[0]
+
+	// Columns are found by header, so exports in any column order import:
+	// Date (else the first column), Total, Notes and group subtotals are
+	// recognised and every other column is a project
+	dateCol, notesCol := 0, -1
+	for j, header := range headers {
+		if strings.EqualFold(strings.TrimSpace(header), "date") {
+			dateCol = j
+			break
+		}
+	}
+	var projectCols []int // Column of each of projectNames
+	var projectNames []string
+	for j, header := range headers {
+		name := strings.TrimSpace(header)
+		lower := strings.ToLower(name)
+		switch {
+		case j == dateCol:
+		case name == "":
+		case lower == "notes":
+			notesCol = j
+		case lower == "total" || strings.HasPrefix(lower, "total "):
+		case strings.HasPrefix(name, subtotalPrefix):
+			// Group subtotals from a grouped export aren't projects
+		default:
+			projectCols = append(projectCols, j)
+			projectNames = append(projectNames, name)
+		}
+	}
 
 	// Process each data row
-	for i, record := range records[1:] {
-		if len(record) == 0 {
+	for i, record := range s.Rows[1:] {
+		if len(record) <= dateCol {
 			continue
 		}
 
 		// Parse date
-		dateStr := strings.TrimSpace(record[0])
+		dateStr := strings.TrimSpace(record[dateCol])
 		if dateStr == "" {
 			continue
 		}
 
 		// Try to parse the date in various formats
-		var parsedDate time.Time
+		dayKey, parsed := format.parseDate(dateStr)
 		dateFormats := []string{
 			"2-Jan",
 			"2-Jan-06",
@@ -62,44 +134,47 @@ func importFromCSV(filename string, config Config) error {
 			"01/02/2006",
 			"1/2/2006",
 		}
-
-		parsed := false
-		for _, format := range dateFormats {
-			if t, err := time.Parse(format, dateStr); err == nil {
-				parsedDate = t
-				// If year is not specified, use current year
-				if format == "2-Jan" {
-					now := time.Now()
-					parsedDate = time.Date(now.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
-				}
-				parsed = true
+		for _, layout := range dateFormats {
+			if parsed {
 				break
 			}
+			dayKey, parsed = sheetFormat{dateLayout: layout, hasYear: layout != "2-Jan"}.parseDate(dateStr)
 		}
 
 		if !parsed {
-			fmt.Printf("Warning: Skipping row %d - invalid date format: %s\n", i+2, dateStr)
+			where := fmt.Sprintf("row %d", i+2)
+			if s.Name != "" {
+				where = fmt.Sprintf("sheet %q %s", s.Name, where)
+			}
+			fmt.Printf("Warning: Skipping %s - invalid date format: %s\n", where, dateStr)
 			continue
 		}
 
-		dayKey := parsedDate.Format("2006-01-02")
-
 		// Create or load day data
 		day := DayData{
 			Date:             dayKey,
-			Projects:         make(map[string]float64),
-			ExcludedMeetings: make(map[string]float64),
+			Projects:         make(map[string]int),
+			ExcludedMeetings: make(map[string]int),
 		}
 
-		if existing, ok := data[dayKey]; ok {
-			day = existing
+		pending, seen := imp.imported[dayKey]
+		if seen {
+			day = pending
+		} else {
+			existing, ok, err := imp.store.LoadDay(dayKey)
+			if err != nil {
+				return err
+			}
+			if ok {
+				day = existing
+			}
 		}
 
-		// Parse project percentages
-		for j, projName := range projectCols {
-			colIdx := j + 1
+		// Parse project values (percentages unless the template says otherwise)
+		for k, colIdx := range projectCols {
+			projName := projectNames[k]
 			if colIdx >= len(record) {
-				break
+				continue
 			}
 
 			valueStr := strings.TrimSpace(record[colIdx])
@@ -107,26 +182,33 @@ func importFromCSV(filename string, config Config) error {
 				continue
 			}
 
-			// Remove % sign if present
-			valueStr = strings.TrimSuffix(valueStr, "%")
-
-			pct, err := strconv.ParseFloat(valueStr, 64)
+			// Timesheet percentages are relative to that day's scheduled length
+			minutes, err := format.parseValue(valueStr, config, dayKey)
 			if err != nil {
-				fmt.Printf("Warning: Invalid percentage for %s on %s: %s\n", projName, dateStr, valueStr)
+				fmt.Printf("Warning: Invalid value for %s on %s: %s\n", projName, dateStr, valueStr)
 				continue
 			}
+			// A template that writes 0 for empty cells means no time, not "set to 0"
+			if minutes == 0 && format.zero {
+				continue
+			}
+			setProjectMinutes(&day, projName, minutes)
+		}
 
-			if day.Projects == nil {
-				day.Projects = make(map[string]float64)
+		if notesCol >= 0 && notesCol < len(record) {
+			for project, note := range parseDayNotes(record[notesCol], projectNames) {
+				setProjectNote(&day, project, note, false)
 			}
-			day.Projects[projName] = pct
 		}
 
-		data[dayKey] = day
-		imported++
+		// Rows without time (the rest of a week, say) don't create days
+		if !seen && len(day.Projects) == 0 {
+			continue
+		}
+		if !seen {
+			imp.order = append(imp.order, dayKey)
+		}
+		imp.imported[dayKey] = day
 	}
-
-	saveData(data)
-	fmt.Printf("Successfully imported %d days from %s\n", imported, filename)
 	return nil
 }
@@ -8,11 +8,17 @@ import (
 	"strings"
 )
 
-func runInteractive(data map[string]DayData, config Config, day DayData) {
+func runInteractive() {
 	reader := bufio.NewReader(os.Stdin)
 
 	for {
-		printStatus(day)
+		err := withData("interactive", func(ctx *Context) error {
+			printStatus(ctx.Today, ctx.Config)
+			return nil
+		})
+		if err != nil {
+			fmt.Println("Error:", err)
+		}
 		fmt.Println()
 		fmt.Println("What would you like to do?")
 		fmt.Println("  1. Add time to project")
@@ -28,15 +34,20 @@ func runInteractive(data map[string]DayData, config Config, day DayData) {
 
 		switch choice {
 		case "1":
-			handleInteractiveAdd(reader, data, config, &day)
+			handleInteractiveAdd(reader)
 		case "2":
-			handleInteractiveExclude(reader, data, &day)
+			handleInteractiveExclude(reader)
 		case "3":
-			handleInteractiveRemove(reader, data, config, &day)
+			handleInteractiveRemove(reader)
 		case "4":
-			handleInteractiveHistory(reader, data)
+			handleInteractiveHistory(reader)
 		case "5":
-			printConfig(config)
+			if err := withData("interactive", func(ctx *Context) error {
+				printConfig(ctx.Config)
+				return nil
+			}); err != nil {
+				fmt.Println("Error:", err)
+			}
 			fmt.Print("\nPress Enter to continue...")
 			reader.ReadString('\n')
 		case "6", "q", "quit", "exit":
@@ -50,7 +61,19 @@ func runInteractive(data map[string]DayData, config Config, day DayData) {
 	}
 }
 
-func handleInteractiveAdd(reader *bufio.Reader, data map[string]DayData, config Config, day *DayData) {
+// pauseInteractive prints err, if any, and waits for Enter
+func pauseInteractive(reader *bufio.Reader, err error) {
+	if err != nil {
+		fmt.Println("Error:", err)
+	}
+	fmt.Print("Press Enter to continue...")
+	reader.ReadString('\n')
+}
+
+// The handlers ask for everything first, then take the lock to load today,
+// apply the change and journal it as one op, so undo works per action
+
+func handleInteractiveAdd(reader *bufio.Reader) {
 	fmt.Print("\nProject name (or alias): ")
 	projectInput, _ := reader.ReadString('\n')
 	projectName := strings.TrimSpace(projectInput)
@@ -58,48 +81,49 @@ func handleInteractiveAdd(reader *bufio.Reader, data map[string]DayData, config
 		return
 	}
 
-	project := resolveProjectWithSuggestions(projectName, config, true)
-
 	fmt.Print("Hours: ")
 	hoursInput, _ := reader.ReadString('\n')
 	hours, err := strconv.ParseFloat(strings.TrimSpace(hoursInput), 64)
 	if err != nil {
 		fmt.Println("Invalid hours")
-		fmt.Print("Press Enter to continue...")
-		reader.ReadString('\n')
+		pauseInteractive(reader, nil)
 		return
 	}
 
-	pct := hoursToPercent(hours)
+	err = withData("interactive add", func(ctx *Context) error {
+		config, day := ctx.Config, ctx.Today
+		project, err := resolveProjectInput(projectName, ctx.Store, config, false)
+		if err != nil {
+			return err
+		}
 
-	// Validate time
-	if pct > 100 {
-		fmt.Printf("⚠️  Warning: %.2f hours is %.1f%% of an 8-hour day (>100%%). Did you mean %.2f hours?\n",
-			hours, pct, hours/10)
-	}
+		minutes := hoursToMinutes(hours)
+		pct := toPercent(minutes, config, day.Date)
 
-	if day.Projects == nil {
-		day.Projects = make(map[string]float64)
-	}
-	day.Projects[project] = pct
-	day.LastModified = project // Track for undo
-	data[today()] = *day
-	saveData(data)
-
-	// Check total allocation
-	total := getTotalTracked(*day)
-	available := getAvailablePercent(*day)
-	if total > available {
-		fmt.Printf("\n✓ Added %.1f%% to %s\n", pct, project)
-		fmt.Printf("⚠️  Warning: Over-allocated by %.1f%%!\n", total-available)
-	} else {
+		// Validate time
+		if pct > 100 {
+			fmt.Printf("⚠️  Warning: %.2f hours is %.1f%% of the %s working day (>100%%). Did you mean %.2f hours?\n",
+				hours, pct, formatDuration(percentBase(config, day.Date)), hours/10)
+		}
+
+		setProjectMinutes(&day, project, minutes)
+		if err := ctx.Store.SaveDay(day); err != nil {
+			return err
+		}
+
+		// Check total allocation
+		total := getTotalTracked(day)
+		available := getAvailableMinutes(day, config)
 		fmt.Printf("\n✓ Added %.1f%% to %s\n", pct, project)
-	}
-	fmt.Print("Press Enter to continue...")
-	reader.ReadString('\n')
+		if total > available {
+			fmt.Printf("⚠️  Warning: Over-allocated by %.1f%%!\n", toPercent(total-available, config, day.Date))
+		}
+		return nil
+	})
+	pauseInteractive(reader, err)
 }
 
-func handleInteractiveExclude(reader *bufio.Reader, data map[string]DayData, day *DayData) {
+func handleInteractiveExclude(reader *bufio.Reader) {
 	fmt.Print("\nMeeting name: ")
 	nameInput, _ := reader.ReadString('\n')
 	name := strings.TrimSpace(nameInput)
@@ -112,71 +136,75 @@ func handleInteractiveExclude(reader *bufio.Reader, data map[string]DayData, day
 	hours, err := strconv.ParseFloat(strings.TrimSpace(hoursInput), 64)
 	if err != nil {
 		fmt.Println("Invalid hours")
-		fmt.Print("Press Enter to continue...")
-		reader.ReadString('\n')
+		pauseInteractive(reader, nil)
 		return
 	}
 
-	pct := hoursToPercent(hours)
-
-	if day.ExcludedMeetings == nil {
-		day.ExcludedMeetings = make(map[string]float64)
-	}
-
-	oldPct := day.ExcludedMeetings[name]
-	day.ExcludedPercent = day.ExcludedPercent - oldPct + pct
-	day.ExcludedMeetings[name] = pct
-
-	data[today()] = *day
-	saveData(data)
+	minutes := hoursToMinutes(hours)
+	err = withData("interactive exclude", func(ctx *Context) error {
+		day := ctx.Today
+		if day.ExcludedMeetings == nil {
+			day.ExcludedMeetings = make(map[string]int)
+		}
+		day.ExcludedMeetings[name] = minutes
 
-	fmt.Printf("\n✓ Excluded %.1f%% for %s\n", pct, name)
-	fmt.Print("Press Enter to continue...")
-	reader.ReadString('\n')
+		if err := ctx.Store.SaveDay(day); err != nil {
+			return err
+		}
+		fmt.Printf("\n✓ Excluded %.1f%% for %s\n", toPercent(minutes, ctx.Config, day.Date), name)
+		return nil
+	})
+	pauseInteractive(reader, err)
 }
 
-func handleInteractiveRemove(reader *bufio.Reader, data map[string]DayData, config Config, day *DayData) {
-	if len(day.Projects) == 0 {
-		fmt.Println("\nNo projects to remove")
-		fmt.Print("Press Enter to continue...")
-		reader.ReadString('\n')
+func handleInteractiveRemove(reader *bufio.Reader) {
+	var projectList []string
+	err := withData("interactive", func(ctx *Context) error {
+		projectList = sortedKeys(ctx.Today.Projects)
+		return nil
+	})
+	if err != nil || len(projectList) == 0 {
+		if err == nil {
+			fmt.Println("\nNo projects to remove")
+		}
+		pauseInteractive(reader, err)
 		return
 	}
 
 	fmt.Println("\nCurrent projects:")
-	i := 1
-	projectList := make([]string, 0)
-	for name := range day.Projects {
-		fmt.Printf("  %d. %s\n", i, name)
-		projectList = append(projectList, name)
-		i++
+	for i, name := range projectList {
+		fmt.Printf("  %d. %s\n", i+1, name)
 	}
 
 	fmt.Print("\nProject number to remove (or name): ")
 	input, _ := reader.ReadString('\n')
 	choice := strings.TrimSpace(input)
 
-	var projectToRemove string
-	if num, err := strconv.Atoi(choice); err == nil && num > 0 && num <= len(projectList) {
-		projectToRemove = projectList[num-1]
-	} else {
-		projectToRemove = resolveProject(choice, config)
-	}
+	err = withData("interactive remove", func(ctx *Context) error {
+		day := ctx.Today
+		var projectToRemove string
+		if num, err := strconv.Atoi(choice); err == nil && num > 0 && num <= len(projectList) {
+			projectToRemove = projectList[num-1]
+		} else {
+			projectToRemove = resolveProject(choice, ctx.Config)
+		}
 
-	if _, ok := day.Projects[projectToRemove]; ok {
-		delete(day.Projects, projectToRemove)
-		data[today()] = *day
-		saveData(data)
+		// Another command may have changed the day since the list was shown
+		if _, ok := day.Projects[projectToRemove]; !ok {
+			fmt.Printf("\nProject '%s' not found\n", projectToRemove)
+			return nil
+		}
+		removeProject(&day, projectToRemove)
+		if err := ctx.Store.SaveDay(day); err != nil {
+			return err
+		}
 		fmt.Printf("\n✓ Removed %s\n", projectToRemove)
-	} else {
-		fmt.Printf("\nProject '%s' not found\n", projectToRemove)
-	}
-
-	fmt.Print("Press Enter to continue...")
-	reader.ReadString('\n')
+		return nil
+	})
+	pauseInteractive(reader, err)
 }
 
-func handleInteractiveHistory(reader *bufio.Reader, data map[string]DayData) {
+func handleInteractiveHistory(reader *bufio.Reader) {
 	fmt.Print("\nHow many days? (default 7): ")
 	input, _ := reader.ReadString('\n')
 	days := 7
@@ -184,7 +212,13 @@ func handleInteractiveHistory(reader *bufio.Reader, data map[string]DayData) {
 		days = d
 	}
 
-	printHistory(data, days)
-	fmt.Print("Press Enter to continue...")
-	reader.ReadString('\n')
+	err := withData("interactive", func(ctx *Context) error {
+		data, err := loadRecentDays(ctx.Store, days)
+		if err != nil {
+			return err
+		}
+		printHistory(data, ctx.Config, days)
+		return nil
+	})
+	pauseInteractive(reader, err)
 }
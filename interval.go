@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Interval is a stretch of time worked on a project, as "15:04" clock times
+// on the day it belongs to. An interval that runs to midnight ends at "24:00".
+type Interval struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// bounds returns the interval as minutes since midnight
+func (iv Interval) bounds() (int, int) {
+	start, _ := parseClock(iv.Start)
+	end, _ := parseClock(iv.End)
+	return start, end
+}
+
+func (iv Interval) minutes() int {
+	start, end := iv.bounds()
+	return end - start
+}
+
+func (iv Interval) String() string {
+	return iv.Start + "-" + iv.End
+}
+
+// parseClock reads "9:15", "09:15" or "24:00" as minutes since midnight
+func parseClock(s string) (int, error) {
+	hStr, mStr, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return 0, fmt.Errorf("invalid time %q (use HH:MM)", s)
+	}
+	h, err1 := strconv.Atoi(hStr)
+	m, err2 := strconv.Atoi(mStr)
+	if err1 != nil || err2 != nil || h < 0 || m < 0 || m > 59 || h > 24 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("invalid time %q (use HH:MM)", s)
+	}
+	return h*60 + m, nil
+}
+
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// isIntervalSpec reports whether an argument looks like "09:15-11:45" rather
+// than a number of hours
+func isIntervalSpec(s string) bool {
+	return strings.Contains(s, ":") && strings.Contains(s, "-")
+}
+
+// parseIntervals reads one or more comma-separated ranges, e.g.
+// "09:15-11:45" or "09:00-10:30,14:00-15:00"
+func parseIntervals(spec string) ([]Interval, error) {
+	var intervals []Interval
+	for _, part := range strings.Split(spec, ",") {
+		startStr, endStr, ok := strings.Cut(strings.TrimSpace(part), "-")
+		if !ok {
+			return nil, fmt.Errorf("invalid time range %q (use HH:MM-HH:MM)", part)
+		}
+		start, err := parseClock(startStr)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseClock(endStr)
+		if err != nil {
+			return nil, err
+		}
+		if end <= start {
+			return nil, fmt.Errorf("time range %s ends before it starts", part)
+		}
+		intervals = append(intervals, Interval{Start: formatClock(start), End: formatClock(end)})
+	}
+	return intervals, nil
+}
+
+// timelineEntry is an interval together with the project it was worked on
+type timelineEntry struct {
+	Project string
+	Interval
+}
+
+// dayTimeline lists every interval on day in start order
+func dayTimeline(day DayData) []timelineEntry {
+	var entries []timelineEntry
+	for project, intervals := range day.Intervals {
+		for _, iv := range intervals {
+			entries = append(entries, timelineEntry{project, iv})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Start != entries[j].Start {
+			return entries[i].Start < entries[j].Start
+		}
+		return entries[i].Project < entries[j].Project
+	})
+	return entries
+}
+
+// findOverlaps describes each clash between the new intervals and those
+// already on the day (or with each other)
+func findOverlaps(day DayData, project string, added []Interval) []string {
+	existing := dayTimeline(day)
+	var overlaps []string
+	for i, iv := range added {
+		start, end := iv.bounds()
+		for _, e := range existing {
+			s, en := e.bounds()
+			if start < en && s < end {
+				overlaps = append(overlaps, fmt.Sprintf("%s overlaps %s %s", iv, e.Project, e.Interval))
+			}
+		}
+		for _, other := range added[:i] {
+			s, en := other.bounds()
+			if start < en && s < end {
+				overlaps = append(overlaps, fmt.Sprintf("%s overlaps %s %s", iv, project, other))
+			}
+		}
+	}
+	return overlaps
+}
+
+// addProjectIntervals records time ranges against project on date, adding
+// their length to the project's total. Ranges that overlap time already
+// recorded are rejected.
+func addProjectIntervals(store Store, config Config, date, project string, intervals []Interval, note string) (DayData, error) {
+	day, err := getDateData(store, config, date)
+	if err != nil {
+		return DayData{}, err
+	}
+	if overlaps := findOverlaps(day, project, intervals); len(overlaps) > 0 {
+		return DayData{}, fmt.Errorf("time ranges overlap:\n  %s", strings.Join(overlaps, "\n  "))
+	}
+
+	if day.Projects == nil {
+		day.Projects = make(map[string]int)
+	}
+	if day.Intervals == nil {
+		day.Intervals = make(map[string][]Interval)
+	}
+	for _, iv := range intervals {
+		day.Projects[project] += iv.minutes()
+		day.Intervals[project] = append(day.Intervals[project], iv)
+	}
+	sort.Slice(day.Intervals[project], func(i, j int) bool {
+		return day.Intervals[project][i].Start < day.Intervals[project][j].Start
+	})
+	setProjectNote(&day, project, note, true)
+
+	if err := store.SaveDay(day); err != nil {
+		return DayData{}, err
+	}
+	return day, nil
+}
+
+// addProjectMinutes adds untimed minutes to a project's total, keeping the
+// time ranges already recorded for it
+func addProjectMinutes(store Store, config Config, date, project string, minutes int) (DayData, error) {
+	day, err := getDateData(store, config, date)
+	if err != nil {
+		return DayData{}, err
+	}
+	if day.Projects == nil {
+		day.Projects = make(map[string]int)
+	}
+	day.Projects[project] += minutes
+	if err := store.SaveDay(day); err != nil {
+		return DayData{}, err
+	}
+	return day, nil
+}
+
+// setProjectMinutes replaces a project's total. Its time ranges are kept
+// while they still fit in the new total; ranges adding up to more are
+// dropped, with a warning.
+func setProjectMinutes(day *DayData, project string, minutes int) {
+	if day.Projects == nil {
+		day.Projects = make(map[string]int)
+	}
+	day.Projects[project] = minutes
+
+	ranges := day.Intervals[project]
+	timed := 0
+	names := make([]string, len(ranges))
+	for i, iv := range ranges {
+		timed += iv.minutes()
+		names[i] = iv.String()
+	}
+	if timed <= minutes {
+		return
+	}
+	delete(day.Intervals, project)
+	fmt.Printf("⚠️  Dropped the time ranges for %s on %s (%s): they add up to more than %s\n",
+		project, day.Date, strings.Join(names, ", "), formatDuration(minutes))
+}
+
+func removeProject(day *DayData, project string) {
+	delete(day.Projects, project)
+	delete(day.Intervals, project)
+	delete(day.Notes, project)
+}
+
+// printTimeline shows the day's time ranges in order, with gaps between them
+func printTimeline(day DayData) {
+	entries := dayTimeline(day)
+	if len(entries) == 0 {
+		return
+	}
+
+	fmt.Println("Timeline:")
+	lastEnd := -1
+	for _, e := range entries {
+		start, end := e.bounds()
+		if lastEnd >= 0 && start > lastEnd {
+			fmt.Printf("   %s%s-%s  %6s  (gap)%s\n", ColorGray, formatClock(lastEnd), e.Start, formatDuration(start-lastEnd), ColorReset)
+		}
+		color := ColorBlue
+		if lastEnd > start {
+			color = ColorRed // Overlaps the previous range
+		}
+		fmt.Printf("   %s%s%s  %6s  %s\n", color, e.Interval, ColorReset, formatDuration(e.minutes()), e.Project)
+		if end > lastEnd {
+			lastEnd = end
+		}
+	}
+	fmt.Println()
+}
@@ -0,0 +1,604 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	JournalOp   = "op"
+	JournalUndo = "undo"
+	JournalRedo = "redo"
+)
+
+// JournalEntry is one line of ~/.timetrack/journal.jsonl. Ops record the
+// before/after state of every day a command touched; undo and redo entries
+// point back at the op they reverted or re-applied.
+type JournalEntry struct {
+	ID            int         `json:"id"`
+	Time          string      `json:"time"`
+	Kind          string      `json:"kind"`
+	Command       string      `json:"command"`
+	Target        int         `json:"target,omitempty"`
+	Reverts       []int       `json:"reverts,omitempty"`        // For an op made by undo --date: the ops it reversed on that day
+	SchemaVersion int         `json:"schema_version,omitempty"` // Of the days in Changes
+	Changes       []DayChange `json:"changes,omitempty"`
+}
+
+// rawJournalEntry defers decoding days until their schema version is known
+type rawJournalEntry struct {
+	JournalEntry
+	Changes []struct {
+		Date   string          `json:"date"`
+		Before json.RawMessage `json:"before"`
+		After  json.RawMessage `json:"after"`
+	} `json:"changes,omitempty"`
+}
+
+// journalFirstVersion is assumed for entries written before they carried a
+// schema version
+const journalFirstVersion = 2
+
+// DayChange holds a day before and after an op. A nil side means the day
+// did not exist (before) or was deleted (after).
+type DayChange struct {
+	Date   string   `json:"date"`
+	Before *DayData `json:"before"`
+	After  *DayData `json:"after"`
+}
+
+// journalStore wraps a Store and remembers the original state of each day
+// written through it, so the whole command can be journaled as one op.
+type journalStore struct {
+	Store
+	changes map[string]*DayChange
+	order   []string
+	reverts []int // Set by undo --date for the op it commits
+}
+
+func newJournalStore(store Store) *journalStore {
+	return &journalStore{Store: store, changes: make(map[string]*DayChange)}
+}
+
+func (j *journalStore) capture(date string) error {
+	if _, ok := j.changes[date]; ok {
+		return nil
+	}
+	before, ok, err := j.Store.LoadDay(date)
+	if err != nil {
+		return err
+	}
+	change := &DayChange{Date: date}
+	if ok {
+		change.Before = &before
+	}
+	j.changes[date] = change
+	j.order = append(j.order, date)
+	return nil
+}
+
+func (j *journalStore) SaveDay(day DayData) error {
+	return j.SaveDays([]DayData{day})
+}
+
+func (j *journalStore) SaveDays(days []DayData) error {
+	for _, day := range days {
+		if err := j.capture(day.Date); err != nil {
+			return err
+		}
+	}
+	if err := j.Store.SaveDays(days); err != nil {
+		return err
+	}
+	for _, day := range days {
+		after := cloneDay(day)
+		j.changes[day.Date].After = &after
+	}
+	return nil
+}
+
+func (j *journalStore) DeleteDay(date string) error {
+	if err := j.capture(date); err != nil {
+		return err
+	}
+	if err := j.Store.DeleteDay(date); err != nil {
+		return err
+	}
+	j.changes[date].After = nil
+	return nil
+}
+
+func (j *journalStore) ReplaceAll(days []DayData) error {
+	dates, err := j.Store.Dates()
+	if err != nil {
+		return err
+	}
+	for _, date := range dates {
+		if err := j.capture(date); err != nil {
+			return err
+		}
+	}
+	for _, day := range days {
+		if err := j.capture(day.Date); err != nil {
+			return err
+		}
+	}
+	if err := j.Store.ReplaceAll(days); err != nil {
+		return err
+	}
+
+	for _, change := range j.changes {
+		change.After = nil
+	}
+	for _, day := range days {
+		after := cloneDay(day)
+		j.changes[day.Date].After = &after
+	}
+	return nil
+}
+
+// Commit appends everything written since the last commit as a single op.
+// Writes that left a day unchanged are dropped.
+func (j *journalStore) Commit(command string) error {
+	var changes []DayChange
+	for _, date := range j.order {
+		change := j.changes[date]
+		if !sameDay(change.Before, change.After) {
+			changes = append(changes, *change)
+		}
+	}
+	reverts := j.reverts
+	j.changes = make(map[string]*DayChange)
+	j.order = nil
+	j.reverts = nil
+
+	if len(changes) == 0 {
+		return nil
+	}
+	return appendJournal(JournalEntry{Kind: JournalOp, Command: command, Reverts: reverts, Changes: changes})
+}
+
+func sameDay(a, b *DayData) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	aBytes, _ := json.Marshal(a)
+	bBytes, _ := json.Marshal(b)
+	return bytes.Equal(aBytes, bBytes)
+}
+
+func loadJournal() ([]JournalEntry, error) {
+	file, err := os.Open(getJournalPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer file.Close()
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var raw rawJournalEntry
+		if err := json.Unmarshal(line, &raw); err != nil {
+			// A crash mid-append can leave a partial last line; skip it
+			continue
+		}
+		entry, err := decodeJournalEntry(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read journal entry #%d: %w", raw.ID, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	return entries, nil
+}
+
+// decodeJournalEntry upgrades the days recorded in an entry to the current schema
+func decodeJournalEntry(raw rawJournalEntry) (JournalEntry, error) {
+	entry := raw.JournalEntry
+	version := entry.SchemaVersion
+	if version == 0 {
+		version = journalFirstVersion
+	}
+
+	entry.Changes = nil
+	for _, c := range raw.Changes {
+		change := DayChange{Date: c.Date}
+		for _, side := range []struct {
+			raw json.RawMessage
+			day **DayData
+		}{{c.Before, &change.Before}, {c.After, &change.After}} {
+			if len(side.raw) == 0 || string(side.raw) == "null" {
+				continue
+			}
+			day, err := decodeDay(side.raw, version, nil)
+			if err != nil {
+				return entry, err
+			}
+			*side.day = &day
+		}
+		entry.Changes = append(entry.Changes, change)
+	}
+	entry.SchemaVersion = dataSchemaVersion
+	return entry, nil
+}
+
+func appendJournal(entry JournalEntry) error {
+	last, partial, err := journalTail()
+	if err != nil {
+		return err
+	}
+	entry.ID = last + 1
+	entry.Time = time.Now().Format(time.RFC3339)
+	if len(entry.Changes) > 0 {
+		entry.SchemaVersion = dataSchemaVersion
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal entry: %w", err)
+	}
+
+	file, err := os.OpenFile(getJournalPath(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer file.Close()
+
+	// Start a fresh line after a partial one left by a crash
+	if partial {
+		line = append([]byte("\n"), line...)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return file.Sync()
+}
+
+// journalTail reads the ID of the last complete journal entry from the end
+// of the file, so appending doesn't cost more as the journal grows. partial
+// reports that the file doesn't end with a newline.
+func journalTail() (last int, partial bool, err error) {
+	file, err := os.Open(getJournalPath())
+	if os.IsNotExist(err) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	const chunk = 64 * 1024
+	var tail []byte
+	for end := info.Size(); end > 0; {
+		start := max(end-chunk, 0)
+		buf := make([]byte, end-start)
+		if _, err := file.ReadAt(buf, start); err != nil {
+			return 0, false, fmt.Errorf("failed to read journal: %w", err)
+		}
+		if tail == nil {
+			partial = buf[len(buf)-1] != '\n'
+		}
+		tail = append(buf, tail...)
+		end = start
+
+		// Only lines after the first newline read are known to be whole;
+		// a partial last line from a crash doesn't parse and is skipped
+		lines := bytes.Split(tail, []byte("\n"))
+		if start > 0 {
+			lines = lines[1:]
+		}
+		for i := len(lines) - 1; i >= 0; i-- {
+			var entry struct {
+				ID int `json:"id"`
+			}
+			if json.Unmarshal(lines[i], &entry) == nil && entry.ID > 0 {
+				return entry.ID, partial, nil
+			}
+		}
+	}
+	return 0, partial, nil
+}
+
+// journalStacks replays the journal and returns the ops that can be undone
+// and redone, most recent last. A new op clears the redo stack.
+func journalStacks(entries []JournalEntry) (done, undone []JournalEntry) {
+	ops := make(map[int]JournalEntry)
+	for _, entry := range entries {
+		switch entry.Kind {
+		case JournalOp:
+			ops[entry.ID] = entry
+			done = append(done, entry)
+			undone = nil
+		case JournalUndo:
+			if n := len(done); n > 0 && done[n-1].ID == entry.Target {
+				done = done[:n-1]
+				undone = append(undone, ops[entry.Target])
+			}
+		case JournalRedo:
+			if n := len(undone); n > 0 && undone[n-1].ID == entry.Target {
+				undone = undone[:n-1]
+				done = append(done, ops[entry.Target])
+			}
+		}
+	}
+	return done, undone
+}
+
+// handleUndo reverts the last n ops, newest first
+func handleUndo(store Store, config Config, n int) error {
+	return stepJournal(store, config, n, true)
+}
+
+// handleRedo re-applies the last n undone ops
+func handleRedo(store Store, config Config, n int) error {
+	return stepJournal(store, config, n, false)
+}
+
+// handleUndoDay puts date back the way it was before the last n ops that
+// changed it. Other days those ops touched are left alone, and the ops stay
+// on the undo stack; the restore is recorded as an op of its own, naming the
+// ops it reversed. Those restores and the ops they reversed are passed over,
+// so running it again steps further back rather than undoing the restore.
+func handleUndoDay(store *journalStore, config Config, date string, n int) error {
+	entries, err := loadJournal()
+	if err != nil {
+		return err
+	}
+	done, _ := journalStacks(entries)
+
+	var ops []JournalEntry
+	var before *DayData
+	reverted := make(map[int]bool)
+	for i := len(done) - 1; i >= 0 && len(ops) < n; i-- {
+		// A restore only reversed those ops on the day it changed
+		if len(done[i].Reverts) > 0 {
+			for _, change := range done[i].Changes {
+				if change.Date != date {
+					continue
+				}
+				for _, id := range done[i].Reverts {
+					reverted[id] = true
+				}
+			}
+			continue
+		}
+		if reverted[done[i].ID] {
+			continue
+		}
+		for _, change := range done[i].Changes {
+			if change.Date == date {
+				ops = append(ops, done[i])
+				before = change.Before
+			}
+		}
+	}
+	if len(ops) == 0 {
+		fmt.Printf("Nothing to undo on %s\n", date)
+		return nil
+	}
+
+	current, ok, err := store.LoadDay(date)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		if ok {
+			if err := store.DeleteDay(date); err != nil {
+				return err
+			}
+		}
+	} else if err := store.SaveDay(*before); err != nil {
+		return err
+	}
+	for _, op := range ops {
+		store.reverts = append(store.reverts, op.ID)
+	}
+
+	for _, op := range ops {
+		fmt.Printf("Undid #%d on %s: %s", op.ID, date, op.Command)
+		if len(op.Changes) > 1 {
+			fmt.Printf(" %s(other days it changed are kept)%s", ColorGray, ColorReset)
+		}
+		fmt.Println()
+	}
+	var currentPtr *DayData
+	if ok {
+		currentPtr = &current
+	}
+	for _, line := range describeChange(DayChange{Date: date, Before: currentPtr, After: before}, false) {
+		fmt.Println("  " + line)
+	}
+	if before != nil {
+		printStatus(*before, config)
+	}
+	return nil
+}
+
+func stepJournal(store Store, config Config, n int, undo bool) error {
+	entries, err := loadJournal()
+	if err != nil {
+		return err
+	}
+	done, undone := journalStacks(entries)
+
+	stack, verb, kind := undone, "Redid", JournalRedo
+	if undo {
+		stack, verb, kind = done, "Undid", JournalUndo
+	}
+	if len(stack) == 0 {
+		if undo {
+			fmt.Println("Nothing to undo")
+		} else {
+			fmt.Println("Nothing to redo")
+		}
+		return nil
+	}
+	if n > len(stack) {
+		n = len(stack)
+	}
+
+	var touched []DayData
+	for i := 0; i < n; i++ {
+		op := stack[len(stack)-1-i]
+		days, err := applyJournalOp(store, op, undo)
+		if err != nil {
+			return err
+		}
+		if err := appendJournal(JournalEntry{Kind: kind, Command: op.Command, Target: op.ID}); err != nil {
+			return err
+		}
+
+		fmt.Printf("%s #%d: %s\n", verb, op.ID, op.Command)
+		for _, change := range op.Changes {
+			for _, line := range describeChange(change, undo) {
+				fmt.Println("  " + line)
+			}
+		}
+		touched = days
+	}
+
+	// Show the resulting day when the last step only touched one
+	if len(touched) == 1 {
+		printStatus(touched[0], config)
+	}
+	return nil
+}
+
+// applyJournalOp writes the before (undo) or after (redo) state of every day
+// in op and returns the days that still exist afterwards.
+func applyJournalOp(store Store, op JournalEntry, undo bool) ([]DayData, error) {
+	var saves []DayData
+	for i := range op.Changes {
+		change := op.Changes[i]
+		target, expected := change.Before, change.After
+		if !undo {
+			target, expected = change.After, change.Before
+		}
+
+		current, ok, err := store.LoadDay(change.Date)
+		if err != nil {
+			return nil, err
+		}
+		var currentPtr *DayData
+		if ok {
+			currentPtr = &current
+		}
+		if !sameDay(currentPtr, expected) {
+			fmt.Printf("⚠️  %s has changed outside the journal since #%d; overwriting\n", change.Date, op.ID)
+		}
+
+		if target == nil {
+			if err := store.DeleteDay(change.Date); err != nil {
+				return nil, err
+			}
+		} else {
+			saves = append(saves, *target)
+		}
+	}
+	if len(saves) > 0 {
+		if err := store.SaveDays(saves); err != nil {
+			return nil, err
+		}
+	}
+	return saves, nil
+}
+
+func describeChange(change DayChange, undo bool) []string {
+	from, to := change.Before, change.After
+	if undo {
+		from, to = to, from
+	}
+	before := make(map[string]DayData)
+	after := make(map[string]DayData)
+	if from != nil {
+		before[change.Date] = *from
+	}
+	if to != nil {
+		after[change.Date] = *to
+	}
+	return diffData(before, after)
+}
+
+// printJournal lists the most recent n journal entries, newest first
+func printJournal(n int) error {
+	entries, err := loadJournal()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Println("No operations recorded yet")
+		return nil
+	}
+
+	_, undone := journalStacks(entries)
+	isUndone := make(map[int]bool)
+	for _, op := range undone {
+		isUndone[op.ID] = true
+	}
+
+	fmt.Println()
+	fmt.Printf("%s📜 Recent operations%s\n", ColorBold, ColorReset)
+	fmt.Println(strings.Repeat("─", 60))
+
+	shown := 0
+	for i := len(entries) - 1; i >= 0 && shown < n; i-- {
+		entry := entries[i]
+		when := entry.Time
+		if t, err := time.Parse(time.RFC3339, entry.Time); err == nil {
+			when = t.Format("02/01/2006 15:04")
+		}
+
+		switch entry.Kind {
+		case JournalOp:
+			status := ""
+			if isUndone[entry.ID] {
+				status = colorize(ColorGray, " (undone)")
+			}
+			fmt.Printf("#%-4d %s  %s%s\n", entry.ID, when, entry.Command, status)
+			for _, change := range entry.Changes {
+				for _, line := range describeChange(change, false) {
+					fmt.Printf("        %s\n", line)
+				}
+			}
+		default:
+			fmt.Printf("#%-4d %s  %s%s #%d%s\n", entry.ID, when, ColorGray, entry.Kind, entry.Target, ColorReset)
+		}
+		shown++
+	}
+	fmt.Println()
+	return nil
+}
+
+// commandLine reconstructs the invoked command for the journal, quoting
+// arguments that contain spaces
+func commandLine(args []string) string {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\"") {
+			parts[i] = strconv.Quote(arg)
+		} else {
+			parts[i] = arg
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,51 @@
+package main
+
+import "testing"
+
+// TestUndoDaySteps checks that undo --date run again steps further back
+// rather than undoing the previous undo
+func TestUndoDaySteps(t *testing.T) {
+	dir := t.TempDir()
+	t.Cleanup(func() { dataDirOverride = "" })
+	run := func(args ...string) {
+		t.Helper()
+		argv := append([]string{"--data-dir", dir, "--quiet"}, args...)
+		if code := runCommand(argv); code != 0 {
+			t.Fatalf("%v exited with %d", args, code)
+		}
+	}
+	alpha := func() (int, bool) {
+		t.Helper()
+		store, err := openStore(Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer store.Close()
+		day, ok, err := store.LoadDay("2025-12-01")
+		if err != nil {
+			t.Fatal(err)
+		}
+		minutes, tracked := day.Projects["alpha"]
+		return minutes, ok && tracked
+	}
+
+	for _, hours := range []string{"1", "2", "3"} {
+		run("add", "alpha", hours, "--date", "2025-12-01")
+	}
+	for _, want := range []int{120, 60} {
+		run("undo", "--date", "2025-12-01")
+		if got, _ := alpha(); got != want {
+			t.Fatalf("after undo --date: alpha %d minutes, want %d", got, want)
+		}
+	}
+	run("undo", "--date", "2025-12-01")
+	if got, ok := alpha(); ok {
+		t.Fatalf("after the third undo --date: alpha %d minutes, want none", got)
+	}
+
+	// Plain undo takes back the last restore
+	run("undo")
+	if got, _ := alpha(); got != 60 {
+		t.Fatalf("after undo: alpha %d minutes, want 60", got)
+	}
+}
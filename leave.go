@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+)
+
+// maxLeaveDays guards against a mistyped year marking years of leave
+const maxLeaveDays = 366
+
+func handleLeave(store Store, config Config, args []string) error {
+	if len(args) == 0 {
+		printLeaveUsage()
+		return nil
+	}
+
+	switch args[0] {
+	case "add":
+		if len(args) < 3 {
+			fmt.Println("Usage: timetrack leave add <from> <to> [type]")
+			return nil
+		}
+		dates, err := dateRange(args[1], args[2])
+		if err != nil {
+			return err
+		}
+		kind := "leave"
+		if len(args) >= 4 {
+			kind = strings.Join(args[3:], " ")
+		}
+		if err := markLeave(store, config, dates, func(string) string { return kind }); err != nil {
+			return err
+		}
+		fmt.Printf("Marked %d day(s) as %s (%s to %s)\n", len(dates), kind, dates[0], dates[len(dates)-1])
+
+	case "rm":
+		if len(args) < 2 {
+			fmt.Println("Usage: timetrack leave rm <from> [to]")
+			return nil
+		}
+		to := args[1]
+		if len(args) >= 3 {
+			to = args[2]
+		}
+		dates, err := dateRange(args[1], to)
+		if err != nil {
+			return err
+		}
+		removed, err := clearLeave(store, config, dates)
+		if err != nil {
+			return err
+		}
+		if removed == 0 {
+			fmt.Println("No leave found in that range")
+			return nil
+		}
+		fmt.Printf("Removed leave from %d day(s)\n", removed)
+
+	case "list":
+		data, err := store.Range("", "")
+		if err != nil {
+			return err
+		}
+		printLeave(data)
+
+	case "import":
+		if len(args) < 2 {
+			fmt.Println("Usage: timetrack leave import <file.ics> [type]")
+			return nil
+		}
+		events, err := loadICS(args[1])
+		if err != nil {
+			return err
+		}
+		labels := make(map[string]string)
+		for _, event := range events {
+			label := event.Summary
+			if len(args) >= 3 {
+				label = strings.Join(args[2:], " ")
+			}
+			if label == "" {
+				label = "holiday"
+			}
+			for _, date := range eventDates(event) {
+				labels[date] = label
+			}
+		}
+		if len(labels) == 0 {
+			fmt.Println("No events found in", args[1])
+			return nil
+		}
+		dates := sortedKeys(labels)
+		if err := markLeave(store, config, dates, func(date string) string { return labels[date] }); err != nil {
+			return err
+		}
+		fmt.Printf("Imported %d non-working day(s) from %s\n", len(dates), args[1])
+
+	default:
+		printLeaveUsage()
+	}
+	return nil
+}
+
+func printLeaveUsage() {
+	fmt.Println("Usage: timetrack leave add <from> <to> [type]")
+	fmt.Println("       timetrack leave rm <from> [to]")
+	fmt.Println("       timetrack leave list")
+	fmt.Println("       timetrack leave import <file.ics> [type]")
+}
+
+// dateRange lists every date from one date to another, inclusive
+func dateRange(fromStr, toStr string) ([]string, error) {
+	from, err := parseDate(fromStr)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseDate(toStr)
+	if err != nil {
+		return nil, err
+	}
+	start, _ := time.Parse("2006-01-02", from)
+	end, _ := time.Parse("2006-01-02", to)
+	if end.Before(start) {
+		return nil, fmt.Errorf("%s is before %s", to, from)
+	}
+	if end.Sub(start) >= maxLeaveDays*24*time.Hour {
+		return nil, fmt.Errorf("range %s to %s is longer than %d days", from, to, maxLeaveDays)
+	}
+
+	var dates []string
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		dates = append(dates, d.Format("2006-01-02"))
+	}
+	return dates, nil
+}
+
+// markLeave flags each date as non-working with the label for that date
+func markLeave(store Store, config Config, dates []string, label func(date string) string) error {
+	days := make([]DayData, 0, len(dates))
+	for _, date := range dates {
+		day, err := getDateData(store, config, date)
+		if err != nil {
+			return err
+		}
+		day.Leave = label(date)
+		days = append(days, day)
+	}
+	return store.SaveDays(days)
+}
+
+// clearLeave removes the leave flag from dates. Days that only existed to
+// hold the flag are deleted so they don't show up as untracked.
+func clearLeave(store Store, config Config, dates []string) (int, error) {
+	var days []DayData
+	removed := 0
+	for _, date := range dates {
+		day, ok, err := store.LoadDay(date)
+		if err != nil {
+			return 0, err
+		}
+		if !ok || !isNonWorking(day) {
+			continue
+		}
+		day.Leave = ""
+		removed++
+		if len(day.Projects) == 0 && maps.Equal(day.ExcludedMeetings, newDay(config, date).ExcludedMeetings) {
+			if err := store.DeleteDay(date); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		days = append(days, day)
+	}
+	if len(days) > 0 {
+		if err := store.SaveDays(days); err != nil {
+			return 0, err
+		}
+	}
+	return removed, nil
+}
+
+// printLeave lists leave as ranges of consecutive days with the same type
+func printLeave(data map[string]DayData) {
+	fmt.Println()
+	fmt.Println("🌴 Holidays & Leave")
+	fmt.Println(strings.Repeat("─", 45))
+
+	type leaveRange struct {
+		from, to, kind string
+		days           int
+	}
+	var ranges []leaveRange
+	for _, date := range sortedDates(data) {
+		day := data[date]
+		if !isNonWorking(day) {
+			continue
+		}
+		if n := len(ranges); n > 0 && ranges[n-1].kind == day.Leave && nextDate(ranges[n-1].to) == date {
+			ranges[n-1].to = date
+			ranges[n-1].days++
+			continue
+		}
+		ranges = append(ranges, leaveRange{from: date, to: date, kind: day.Leave, days: 1})
+	}
+
+	if len(ranges) == 0 {
+		fmt.Println("No leave recorded")
+		fmt.Println()
+		return
+	}
+	for _, r := range ranges {
+		if r.days == 1 {
+			fmt.Printf("   • %s  %s\n", r.from, r.kind)
+		} else {
+			fmt.Printf("   • %s to %s  %s (%d days)\n", r.from, r.to, r.kind, r.days)
+		}
+	}
+	fmt.Println()
+}
+
+func nextDate(date string) string {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return ""
+	}
+	return t.AddDate(0, 0, 1).Format("2006-01-02")
+}
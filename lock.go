@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	// commandLockTimeout is how long a CLI command waits for another one to finish
+	commandLockTimeout = 5 * time.Second
+	// reminderLockTimeout is kept short so the daemon never blocks a command for long
+	reminderLockTimeout = 2 * time.Second
+)
+
+var errLocked = errors.New("lock held by another process")
+
+// fileLock is an advisory lock on ~/.timetrack/timetrack.lock guarding the
+// read-modify-write cycle on data and config files.
+type fileLock struct {
+	file *os.File
+}
+
+// acquireLock waits up to timeout for the data lock. Exclusive locks are for
+// commands that may write; shared locks are enough for read-only access.
+func acquireLock(exclusive bool, timeout time.Duration) (*fileLock, error) {
+	file, err := os.OpenFile(getLockPath(), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err = lockFile(file, exclusive)
+		if err == nil {
+			return &fileLock{file: file}, nil
+		}
+		if !errors.Is(err, errLocked) {
+			file.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", getLockPath(), err)
+		}
+		if time.Now().After(deadline) {
+			file.Close()
+			return nil, fmt.Errorf("timetrack data is in use by another process (waited %s for %s)", timeout, getLockPath())
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func (l *fileLock) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	unlockFile(l.file)
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// Schema versions written by this build. Unversioned files are version 1.
+const (
+	dataSchemaVersion   = 5
+	configSchemaVersion = 2
+)
+
+// legacyDayMinutes is the 8-hour day that stored percentages were relative
+// to before durations were kept in minutes
+const legacyDayMinutes = 8 * 60
+
+// migration upgrades a single JSON document (one day, or the whole config)
+// to version. apply reports whether it changed anything.
+type migration struct {
+	version     int
+	description string
+	apply       func(doc map[string]any) bool
+}
+
+// dayMigrations run in order on every stored day older than their version
+var dayMigrations = []migration{
+	{2, "drop last_modified (undo now uses the operation journal)", func(day map[string]any) bool {
+		_, ok := day["last_modified"]
+		delete(day, "last_modified")
+		return ok
+	}},
+	{3, "store durations as minutes instead of percentages of an 8-hour day", func(day map[string]any) bool {
+		delete(day, "excluded_percent")
+		projects := percentsToMinutes(day["projects"])
+		meetings := percentsToMinutes(day["excluded_meetings"])
+		return projects || meetings
+	}},
+	{4, "record start/end times for project time (existing days are unchanged)", func(day map[string]any) bool {
+		// Intervals are optional, so nothing needs converting. The version bump
+		// stops older builds, which would drop them, from opening the data.
+		return false
+	}},
+	{5, "attach notes to project entries (existing days are unchanged)", func(day map[string]any) bool {
+		return false
+	}},
+}
+
+// configMigrations run in order on config.json
+var configMigrations = []migration{
+	{2, "store recurring meeting durations as minutes", func(config map[string]any) bool {
+		meetings, _ := config["recurring_meetings"].([]any)
+		for _, m := range meetings {
+			if meeting, ok := m.(map[string]any); ok {
+				pct, _ := meeting["percent"].(float64)
+				meeting["minutes"] = percentToMinutes(pct)
+				delete(meeting, "percent")
+			}
+		}
+		return len(meetings) > 0
+	}},
+}
+
+// percentsToMinutes converts a JSON object of name → percentage in place
+func percentsToMinutes(value any) bool {
+	entries, _ := value.(map[string]any)
+	for name, v := range entries {
+		pct, _ := v.(float64)
+		entries[name] = percentToMinutes(pct)
+	}
+	return len(entries) > 0
+}
+
+func percentToMinutes(pct float64) int {
+	return int(math.Round(pct / 100.0 * legacyDayMinutes))
+}
+
+type migrationStep struct {
+	Version     int
+	Description string
+	Changed     int
+}
+
+// migrationReport summarises an upgrade from one schema version to another
+type migrationReport struct {
+	From        int
+	To          int
+	Steps       []migrationStep
+	Unversioned bool // File predates schema_version and needs it recorded
+}
+
+func (r migrationReport) needed() bool {
+	return r.From < r.To || r.Unversioned
+}
+
+func newMigrationReport(from int, migrations []migration, to int) migrationReport {
+	report := migrationReport{From: from, To: to}
+	for _, m := range migrations {
+		if m.version > from {
+			report.Steps = append(report.Steps, migrationStep{Version: m.version, Description: m.description})
+		}
+	}
+	if to < from {
+		report.To = from
+	}
+	return report
+}
+
+// applyMigrations upgrades doc from version from, counting changes in report
+// (which may be nil)
+func applyMigrations(doc map[string]any, from int, migrations []migration, report *migrationReport) {
+	for _, m := range migrations {
+		if m.version <= from {
+			continue
+		}
+		if m.apply(doc) && report != nil {
+			for i := range report.Steps {
+				if report.Steps[i].Version == m.version {
+					report.Steps[i].Changed++
+				}
+			}
+		}
+	}
+}
+
+func checkSchemaVersion(what string, version, supported int) error {
+	if version > supported {
+		return fmt.Errorf("%s uses schema version %d, but this timetrack only understands up to %d - please upgrade", what, version, supported)
+	}
+	return nil
+}
+
+// decodeDays converts raw day objects at schema version from into DayData,
+// upgrading them in memory on the way
+func decodeDays(raw map[string]json.RawMessage, from int) (map[string]DayData, migrationReport, error) {
+	report := newMigrationReport(from, dayMigrations, dataSchemaVersion)
+	data := make(map[string]DayData, len(raw))
+	for date, bytes := range raw {
+		day, err := decodeDay(bytes, from, &report)
+		if err != nil {
+			return nil, report, fmt.Errorf("failed to decode %s: %w", date, err)
+		}
+		data[date] = day
+	}
+	return data, report, nil
+}
+
+func decodeDay(bytes []byte, from int, report *migrationReport) (DayData, error) {
+	var day DayData
+	if from >= dataSchemaVersion {
+		err := json.Unmarshal(bytes, &day)
+		return day, err
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(bytes, &doc); err != nil {
+		return day, err
+	}
+	applyMigrations(doc, from, dayMigrations, report)
+	upgraded, err := json.Marshal(doc)
+	if err != nil {
+		return day, err
+	}
+	err = json.Unmarshal(upgraded, &day)
+	return day, err
+}
+
+// migrateConfigBytes upgrades a raw config.json document to the current schema
+func migrateConfigBytes(bytes []byte) ([]byte, migrationReport, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(bytes, &doc); err != nil {
+		return nil, migrationReport{}, fmt.Errorf("failed to parse %s: %w", getConfigPath(), err)
+	}
+
+	from := 1
+	v, versioned := doc["schema_version"].(float64)
+	if versioned {
+		from = int(v)
+	}
+	if err := checkSchemaVersion(getConfigPath(), from, configSchemaVersion); err != nil {
+		return nil, migrationReport{}, err
+	}
+
+	report := newMigrationReport(from, configMigrations, configSchemaVersion)
+	report.Unversioned = !versioned
+	if !report.needed() {
+		return bytes, report, nil
+	}
+	applyMigrations(doc, from, configMigrations, &report)
+	doc["schema_version"] = configSchemaVersion
+	upgraded, err := json.Marshal(doc)
+	return upgraded, report, err
+}
+
+// runMigrate upgrades config and data files to the current schema, or with
+// dryRun just reports what would change
+func runMigrate(dryRun bool) error {
+	configReport := migrationReport{From: configSchemaVersion, To: configSchemaVersion}
+	bytes, err := readConfigFile()
+	if err != nil {
+		return err
+	}
+	if bytes != nil {
+		if _, configReport, err = migrateConfigBytes(bytes); err != nil {
+			return err
+		}
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	var dataReport migrationReport
+	switch storageBackend(config) {
+	case StorageSQLite:
+		store, err := openSQLite(getDatabasePath(), newBackupPolicy(config))
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+		dataReport, err = store.migrate(dryRun)
+		if err != nil {
+			return err
+		}
+	default:
+		store, err := newJSONStore(getDataPath(), newBackupPolicy(config))
+		if err != nil {
+			return err
+		}
+		dataReport, err = store.migrate(dryRun)
+		if err != nil {
+			return err
+		}
+	}
+
+	printMigrationReport(getConfigPath(), configReport)
+	printMigrationReport(storagePath(config), dataReport)
+
+	if !configReport.needed() && !dataReport.needed() {
+		fmt.Println("Everything is up to date")
+		return nil
+	}
+	if dryRun {
+		fmt.Println("Dry run - nothing was changed. Run 'timetrack migrate' to apply.")
+		return nil
+	}
+	if configReport.needed() {
+		if err := saveConfig(config); err != nil {
+			return err
+		}
+	}
+	fmt.Println("Migration complete")
+	return nil
+}
+
+func printMigrationReport(path string, report migrationReport) {
+	if !report.needed() {
+		fmt.Printf("%s: schema version %d (current)\n", path, report.From)
+		return
+	}
+	if report.From == report.To {
+		fmt.Printf("%s: schema version %d (version number not yet recorded)\n", path, report.From)
+		return
+	}
+	fmt.Printf("%s: schema version %d → %d\n", path, report.From, report.To)
+	for _, step := range report.Steps {
+		fmt.Printf("   • v%d: %s", step.Version, step.Description)
+		if step.Changed > 0 {
+			fmt.Printf(" (%d affected)", step.Changed)
+		}
+		fmt.Println()
+	}
+}
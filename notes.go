@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// getNoteFlag pulls "-m <note>" or "--message <note>" out of args
+func getNoteFlag(args []string) (string, []string, error) {
+	note := ""
+	remainingArgs := []string{}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "-m" || arg == "--message" {
+			if i+1 >= len(args) {
+				return "", nil, fmt.Errorf("%s flag requires a note", arg)
+			}
+			note = strings.TrimSpace(args[i+1])
+			i++ // Skip the note
+		} else {
+			remainingArgs = append(remainingArgs, arg)
+		}
+	}
+
+	return note, remainingArgs, nil
+}
+
+// setProjectNote attaches a note to a project's entry. When appending (time
+// added on top of what was there) an existing different note is kept and the
+// new one added after it.
+func setProjectNote(day *DayData, project, note string, appendNote bool) {
+	if note == "" {
+		return
+	}
+	if day.Notes == nil {
+		day.Notes = make(map[string]string)
+	}
+	existing := day.Notes[project]
+	if appendNote && existing != "" && existing != note {
+		note = existing + "; " + note
+	}
+	day.Notes[project] = note
+}
+
+// formatDayNotes renders a day's notes as "project: note; project: note" for
+// a single CSV column
+func formatDayNotes(day DayData) string {
+	parts := make([]string, 0, len(day.Notes))
+	for _, project := range sortedKeys(day.Notes) {
+		parts = append(parts, project+": "+day.Notes[project])
+	}
+	return strings.Join(parts, "; ")
+}
+
+// parseDayNotes reverses formatDayNotes. A piece only starts a new note when
+// it begins with one of the known projects, so notes containing "; " survive.
+func parseDayNotes(s string, projects []string) map[string]string {
+	known := make(map[string]bool, len(projects))
+	for _, p := range projects {
+		known[p] = true
+	}
+
+	notes := make(map[string]string)
+	current := ""
+	for _, piece := range strings.Split(s, "; ") {
+		if project, note, ok := strings.Cut(piece, ": "); ok && known[project] {
+			current = project
+			notes[project] = note
+			continue
+		}
+		if current != "" {
+			notes[current] += "; " + piece
+		}
+	}
+	return notes
+}
+
+// printNotes lists every note, newest first, optionally only those whose
+// note or project contains query
+func printNotes(data map[string]DayData, query string) {
+	query = strings.ToLower(query)
+	dates := make([]string, 0, len(data))
+	for date := range data {
+		dates = append(dates, date)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(dates)))
+
+	fmt.Println()
+	fmt.Println("📝 Notes")
+	fmt.Println(strings.Repeat("─", 60))
+
+	found := 0
+	for _, date := range dates {
+		day := data[date]
+		for _, project := range sortedKeys(day.Notes) {
+			note := day.Notes[project]
+			if query != "" && !strings.Contains(strings.ToLower(note), query) &&
+				!strings.Contains(strings.ToLower(project), query) {
+				continue
+			}
+			fmt.Printf("%s  %s%-20s%s %6s  %s\n", date, ColorBlue, project, ColorReset,
+				formatDuration(day.Projects[project]), note)
+			found++
+		}
+	}
+
+	if found == 0 {
+		fmt.Println("No notes found")
+	}
+	fmt.Println()
+}
@@ -5,9 +5,15 @@ import (
 	"path/filepath"
 )
 
+// dataDirOverride is set by --data-dir
+var dataDirOverride string
+
 func getDataDir() string {
-	home, _ := os.UserHomeDir()
-	dir := filepath.Join(home, ".timetrack")
+	dir := dataDirOverride
+	if dir == "" {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".timetrack")
+	}
 	os.MkdirAll(dir, 0755)
 	return dir
 }
@@ -16,10 +22,30 @@ func getDataPath() string {
 	return filepath.Join(getDataDir(), "data.json")
 }
 
+func getDatabasePath() string {
+	return filepath.Join(getDataDir(), "data.db")
+}
+
+func getBackupDir() string {
+	return filepath.Join(getDataDir(), "backups")
+}
+
+func getJournalPath() string {
+	return filepath.Join(getDataDir(), "journal.jsonl")
+}
+
 func getConfigPath() string {
 	return filepath.Join(getDataDir(), "config.json")
 }
 
+func getTimerPath() string {
+	return filepath.Join(getDataDir(), "timer.json")
+}
+
+func getLockPath() string {
+	return filepath.Join(getDataDir(), "timetrack.lock")
+}
+
 func getPidPath() string {
 	return filepath.Join(getDataDir(), "daemon.pid")
 }
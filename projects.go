@@ -0,0 +1,451 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Ways reports and exports can group projects
+var projectGroupings = []string{"category", "client", "tag", "billable"}
+
+// handleProjectMeta implements "projects meta <project> [key=value ...]".
+// With no key=value pairs it shows the project's metadata.
+func handleProjectMeta(config Config, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	project := resolveProject(args[0], config)
+	meta := config.ProjectInfo[project]
+
+	if len(args) == 1 {
+		printProjectMeta(project, meta)
+		return nil
+	}
+
+	for _, pair := range args[1:] {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("expected key=value, got %q", pair)
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(key) {
+		case "category":
+			meta.Category = value
+		case "client":
+			meta.Client = value
+		case "billable":
+			switch strings.ToLower(value) {
+			case "yes", "y", "true":
+				meta.Billable = true
+			case "no", "n", "false", "":
+				meta.Billable = false
+			default:
+				return fmt.Errorf("billable must be yes or no, got %q", value)
+			}
+		case "tags", "tag":
+			meta.Tags = nil
+			for _, tag := range strings.Split(value, ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					meta.Tags = append(meta.Tags, tag)
+				}
+			}
+		default:
+			return fmt.Errorf("unknown field %q (use category, client, billable or tags)", key)
+		}
+	}
+
+	if config.ProjectInfo == nil {
+		config.ProjectInfo = make(map[string]ProjectMeta)
+	}
+	if meta.isEmpty() {
+		delete(config.ProjectInfo, project)
+	} else {
+		config.ProjectInfo[project] = meta
+	}
+	if err := saveConfig(config); err != nil {
+		return err
+	}
+	printProjectMeta(project, meta)
+	return nil
+}
+
+// merge fills m's empty fields from other and adds other's tags. It returns
+// the fields of other that were dropped because m already had a different
+// value, e.g. `client "Acme"`.
+func (m *ProjectMeta) merge(other ProjectMeta) []string {
+	var dropped []string
+	if m.Category == "" {
+		m.Category = other.Category
+	} else if other.Category != "" && other.Category != m.Category {
+		dropped = append(dropped, fmt.Sprintf("category %q", other.Category))
+	}
+	if m.Client == "" {
+		m.Client = other.Client
+	} else if other.Client != "" && other.Client != m.Client {
+		dropped = append(dropped, fmt.Sprintf("client %q", other.Client))
+	}
+	m.Billable = m.Billable || other.Billable
+	for _, tag := range other.Tags {
+		if !containsName(m.Tags, tag) {
+			m.Tags = append(m.Tags, tag)
+		}
+	}
+	return dropped
+}
+
+func (m ProjectMeta) isEmpty() bool {
+	return m.Category == "" && m.Client == "" && !m.Billable && len(m.Tags) == 0
+}
+
+// String summarises the metadata on one line, e.g. "Engineering · Acme · billable · #sso"
+func (m ProjectMeta) String() string {
+	var parts []string
+	if m.Category != "" {
+		parts = append(parts, m.Category)
+	}
+	if m.Client != "" {
+		parts = append(parts, m.Client)
+	}
+	if m.Billable {
+		parts = append(parts, "billable")
+	}
+	for _, tag := range m.Tags {
+		parts = append(parts, "#"+tag)
+	}
+	return strings.Join(parts, " · ")
+}
+
+func printProjectMeta(project string, meta ProjectMeta) {
+	fmt.Printf("%s%s%s\n", ColorBold, project, ColorReset)
+	fmt.Printf("   Category: %s\n", orNone(meta.Category))
+	fmt.Printf("   Client:   %s\n", orNone(meta.Client))
+	billable := "no"
+	if meta.Billable {
+		billable = "yes"
+	}
+	fmt.Printf("   Billable: %s\n", billable)
+	fmt.Printf("   Tags:     %s\n", orNone(strings.Join(meta.Tags, ", ")))
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
+
+// parseGroupFlag pulls "--by <grouping>" out of args
+func parseGroupFlag(args []string) (string, []string, error) {
+	groupBy := ""
+	remainingArgs := []string{}
+
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--by" || args[i] == "--group" {
+			if i+1 >= len(args) {
+				return "", nil, fmt.Errorf("%s flag requires one of: %s", args[i], strings.Join(projectGroupings, ", "))
+			}
+			groupBy = strings.ToLower(args[i+1])
+			i++
+			valid := false
+			for _, g := range projectGroupings {
+				if g == groupBy {
+					valid = true
+				}
+			}
+			if !valid {
+				return "", nil, fmt.Errorf("unknown grouping %q (use %s)", groupBy, strings.Join(projectGroupings, ", "))
+			}
+		} else {
+			remainingArgs = append(remainingArgs, args[i])
+		}
+	}
+
+	return groupBy, remainingArgs, nil
+}
+
+// projectGroups returns the groups a project belongs to. Only tags can put
+// a project in more than one group.
+func projectGroups(project string, config Config, groupBy string) []string {
+	meta := config.ProjectInfo[project]
+	switch groupBy {
+	case "category":
+		if meta.Category == "" {
+			return []string{"(no category)"}
+		}
+		return []string{meta.Category}
+	case "client":
+		if meta.Client == "" {
+			return []string{"(no client)"}
+		}
+		return []string{meta.Client}
+	case "tag":
+		if len(meta.Tags) == 0 {
+			return []string{"(untagged)"}
+		}
+		return meta.Tags
+	case "billable":
+		if meta.Billable {
+			return []string{"Billable"}
+		}
+		return []string{"Non-billable"}
+	}
+	return nil
+}
+
+// primaryGroup is the single group used where a project can only appear once,
+// such as a CSV column
+func primaryGroup(project string, config Config, groupBy string) string {
+	return projectGroups(project, config, groupBy)[0]
+}
+
+// projectGroup is a set of projects with their combined time
+type projectGroup struct {
+	Name     string
+	Total    int
+	Projects []string // Busiest first
+}
+
+// groupProjectTotals buckets per-project minutes into groups, busiest first
+func groupProjectTotals(totals map[string]int, config Config, groupBy string) []projectGroup {
+	byName := make(map[string]*projectGroup)
+	for project, minutes := range totals {
+		for _, name := range projectGroups(project, config, groupBy) {
+			g, ok := byName[name]
+			if !ok {
+				g = &projectGroup{Name: name}
+				byName[name] = g
+			}
+			g.Total += minutes
+			g.Projects = append(g.Projects, project)
+		}
+	}
+
+	groups := make([]projectGroup, 0, len(byName))
+	for _, g := range byName {
+		sort.Slice(g.Projects, func(i, j int) bool {
+			if totals[g.Projects[i]] != totals[g.Projects[j]] {
+				return totals[g.Projects[i]] > totals[g.Projects[j]]
+			}
+			return g.Projects[i] < g.Projects[j]
+		})
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Total != groups[j].Total {
+			return groups[i].Total > groups[j].Total
+		}
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
+
+// printGroupedTotals lists each group's subtotal with its projects beneath it
+func printGroupedTotals(totals map[string]int, config Config, groupBy string) {
+	var grandTotal int
+	for _, minutes := range totals {
+		grandTotal += minutes
+	}
+	if grandTotal == 0 {
+		return
+	}
+
+	fmt.Printf("\n%sTime by %s:%s\n", ColorBold, strings.ToUpper(groupBy[:1])+groupBy[1:], ColorReset)
+	for _, g := range groupProjectTotals(totals, config, groupBy) {
+		share := float64(g.Total) / float64(grandTotal) * 100
+		fmt.Printf("  %s %s%-24s%s %7s  %s%.0f%%%s\n", progressBar(share, 15), ColorBold, g.Name, ColorReset,
+			formatDuration(g.Total), ColorCyan, share, ColorReset)
+		for _, project := range g.Projects {
+			fmt.Printf("  %s    %-22s %7s\n", strings.Repeat(" ", 17), project, formatDuration(totals[project]))
+		}
+	}
+	if groupBy == "tag" {
+		fmt.Printf("  %sProjects with several tags count towards each of them%s\n", ColorGray, ColorReset)
+	}
+}
+
+// isArchived reports whether a project has been archived. Archived projects
+// keep their history but drop out of suggestions and weekly columns.
+func isArchived(project string, config Config) bool {
+	for _, p := range config.Archived {
+		if strings.EqualFold(p, project) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleProjectArchive implements "projects archive|unarchive <project>"
+func handleProjectArchive(config Config, args []string, archive bool) error {
+	if len(args) == 0 {
+		if !archive {
+			return errUsage
+		}
+		if len(config.Archived) == 0 {
+			fmt.Println("No archived projects")
+			return nil
+		}
+		fmt.Println("Archived projects:")
+		for _, p := range config.Archived {
+			fmt.Printf("  %s\n", p)
+		}
+		return nil
+	}
+	project := resolveProject(strings.Join(args, " "), config)
+
+	if archive {
+		if isArchived(project, config) {
+			fmt.Printf("%s is already archived\n", project)
+			return nil
+		}
+		config.Archived = append(config.Archived, project)
+		sort.Strings(config.Archived)
+	} else {
+		if !isArchived(project, config) {
+			fmt.Printf("%s is not archived\n", project)
+			return nil
+		}
+		config.Archived = removeName(config.Archived, project)
+	}
+
+	if err := saveConfig(config); err != nil {
+		return err
+	}
+	if archive {
+		fmt.Printf("📦 Archived %s; its history is kept but it won't be suggested or shown in weekly exports\n", project)
+	} else {
+		fmt.Printf("Unarchived %s\n", project)
+	}
+	return nil
+}
+
+// removeName drops name from names, ignoring case
+func removeName(names []string, name string) []string {
+	kept := names[:0]
+	for _, n := range names {
+		if !strings.EqualFold(n, name) {
+			kept = append(kept, n)
+		}
+	}
+	return kept
+}
+
+// renameProject moves all of from's history to to. When to already has time
+// (a merge) the minutes are added together, time ranges combined and notes
+// joined. The config's aliases, project list, metadata and archive list and
+// a running timer follow the rename.
+func renameProject(store Store, config Config, from, to string, merge bool) error {
+	if from == to {
+		return fmt.Errorf("%s and %s are the same project", from, to)
+	}
+	data, err := store.Range("", "")
+	if err != nil {
+		return err
+	}
+
+	var changed []DayData
+	fromDays, toDays := 0, 0
+	for _, date := range sortedKeys(data) {
+		day := data[date]
+		if _, ok := day.Projects[to]; ok {
+			toDays++
+		}
+		if _, ok := day.Projects[from]; ok {
+			fromDays++
+		}
+	}
+	if fromDays == 0 {
+		return fmt.Errorf("no time recorded for %s", from)
+	}
+	if toDays > 0 && !merge {
+		return fmt.Errorf("%s already has time on %d day(s); use 'timetrack projects merge %s %s' to combine them", to, toDays, from, to)
+	}
+
+	for _, date := range sortedKeys(data) {
+		day := cloneDay(data[date])
+		minutes, ok := day.Projects[from]
+		if !ok {
+			continue
+		}
+		intervals := day.Intervals[from]
+		note := day.Notes[from]
+		removeProject(&day, from)
+
+		day.Projects[to] += minutes
+		if len(intervals) > 0 {
+			if day.Intervals == nil {
+				day.Intervals = make(map[string][]Interval)
+			}
+			day.Intervals[to] = append(day.Intervals[to], intervals...)
+			sort.Slice(day.Intervals[to], func(i, j int) bool {
+				return day.Intervals[to][i].Start < day.Intervals[to][j].Start
+			})
+		}
+		setProjectNote(&day, to, note, true)
+		changed = append(changed, day)
+	}
+	if err := store.SaveDays(changed); err != nil {
+		return err
+	}
+
+	for alias, target := range config.Aliases {
+		if target == from {
+			config.Aliases[alias] = to
+		}
+	}
+	var projects []string
+	for _, p := range config.Projects {
+		if p == from {
+			p = to
+		}
+		if !containsName(projects, p) {
+			projects = append(projects, p)
+		}
+	}
+	config.Projects = projects
+	if meta, ok := config.ProjectInfo[from]; ok {
+		delete(config.ProjectInfo, from)
+		target := config.ProjectInfo[to]
+		for _, field := range target.merge(meta) {
+			fmt.Printf("⚠️  Dropped %s's %s: %s already has one\n", from, field, to)
+		}
+		config.ProjectInfo[to] = target
+	}
+	if isArchived(from, config) {
+		config.Archived = removeName(config.Archived, from)
+		if !merge {
+			config.Archived = append(config.Archived, to)
+			sort.Strings(config.Archived)
+		}
+	}
+	if err := saveConfig(config); err != nil {
+		return err
+	}
+
+	running, err := loadTimer()
+	if err != nil {
+		return err
+	}
+	if running != nil && running.Project == from {
+		running.Project = to
+		if err := saveTimer(*running); err != nil {
+			return err
+		}
+	}
+
+	verb := "Renamed"
+	if merge {
+		verb = "Merged"
+	}
+	fmt.Printf("%s %s into %s on %d day(s)\n", verb, from, to, len(changed))
+	return nil
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
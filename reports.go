@@ -7,54 +7,79 @@ import (
 	"time"
 )
 
-func generateWeeklyReport(data map[string]DayData) {
-	now := time.Now()
-	weekday := int(now.Weekday())
-	if weekday == 0 {
-		weekday = 7
-	}
-	monday := now.AddDate(0, 0, -(weekday - 1))
-	sunday := monday.AddDate(0, 0, 6)
-
+// generateWeeklyReport summarises the days in r, the current week unless
+// another range was given. groupBy ("category", "client", "tag", "billable"
+// or empty) adds subtotals by project metadata.
+func generateWeeklyReport(data map[string]DayData, config Config, r dayRange, groupBy string) {
+	period := "week"
 	fmt.Println()
-	fmt.Printf("%s📊 Weekly Report%s\n", ColorBold, ColorReset)
-	fmt.Printf("%s to %s\n", monday.Format("Jan 2"), sunday.Format("Jan 2, 2006"))
+	if r.isWeek() {
+		fmt.Printf("%s📊 Weekly Report%s\n", ColorBold, ColorReset)
+	} else {
+		period = "total"
+		fmt.Printf("%s📊 Report: %s%s\n", ColorBold, r.Label, ColorReset)
+	}
+	dates := r.dates(data)
+	if len(dates) > 0 {
+		first, last := mustParseDay(dates[0]), mustParseDay(dates[len(dates)-1])
+		fmt.Printf("%s to %s\n", first.Format("Jan 2"), last.Format("Jan 2, 2006"))
+	}
 	fmt.Println(strings.Repeat("─", 60))
 
-	projectTotals := make(map[string]float64)
-	var totalTracked float64
-	var totalAvailable float64
+	projectTotals := make(map[string]int)
+	var totalTracked int
+	var totalAvailable int
 	daysTracked := 0
+	workingDays := 0
+	var leaveDays []string
 
-	// Collect data for the week
-	for i := range 7 {
-		date := monday.AddDate(0, 0, i)
-		dateStr := date.Format("2006-01-02")
+	// Collect data for the range. Leave days don't count towards the days to
+	// track, but any time logged on them still counts.
+	for _, dateStr := range dates {
+		date := mustParseDay(dateStr)
 
-		if day, exists := data[dateStr]; exists && len(day.Projects) > 0 {
-			daysTracked++
-			available := getAvailablePercent(day)
-			tracked := getTotalTracked(day)
+		day, exists := data[dateStr]
+		if !exists {
+			day = DayData{Date: dateStr}
+		}
+		if isNonWorking(day) {
+			leaveDays = append(leaveDays, fmt.Sprintf("%s (%s)", date.Format("Mon"), day.Leave))
+		} else if isWorkingDay(day, config) {
+			workingDays++
+		}
 
-			totalAvailable += available
-			totalTracked += tracked
+		if len(day.Projects) > 0 {
+			if !isNonWorking(day) {
+				daysTracked++
+				totalAvailable += getAvailableMinutes(day, config)
+			}
+			totalTracked += getTotalTracked(day)
 
-			for project, pct := range day.Projects {
-				projectTotals[project] += pct
+			for project, minutes := range day.Projects {
+				projectTotals[project] += minutes
 			}
 		}
 	}
 
-	if daysTracked == 0 {
-		fmt.Println("No data for this week")
+	if totalTracked == 0 && len(leaveDays) == 0 {
+		if r.isWeek() {
+			fmt.Println("No data for this week")
+		} else {
+			fmt.Println("No data for this range")
+		}
 		return
 	}
 
 	fmt.Printf("\n%sSummary:%s\n", ColorBold, ColorReset)
-	fmt.Printf("  Days tracked: %d/7\n", daysTracked)
-	fmt.Printf("  Total available: %.1f%%\n", totalAvailable)
-	fmt.Printf("  Total tracked: %s%.1f%%%s\n", ColorBlue, totalTracked, ColorReset)
-	fmt.Printf("  Average per day: %.1f%%\n", totalTracked/float64(daysTracked))
+	fmt.Printf("  Days tracked: %d/%d\n", daysTracked, workingDays)
+	if len(leaveDays) > 0 {
+		fmt.Printf("  Leave: %s\n", strings.Join(leaveDays, ", "))
+	}
+	fmt.Printf("  Total available: %.1f%% (%s)\n", toPercent(totalAvailable, config, ""), formatDuration(totalAvailable))
+	fmt.Printf("  Total tracked: %s%.1f%%%s (%s)\n", ColorBlue, toPercent(totalTracked, config, ""), ColorReset, formatDuration(totalTracked))
+	if daysTracked > 0 {
+		fmt.Printf("  Average per day: %.1f%%\n", toPercent(totalTracked, config, "")/float64(daysTracked))
+	}
 
 	if len(projectTotals) > 0 {
 		fmt.Printf("\n%sTime by Project:%s\n", ColorBold, ColorReset)
@@ -62,7 +87,7 @@ func generateWeeklyReport(data map[string]DayData) {
 		// Sort projects by time spent
 		type projectTime struct {
 			name  string
-			total float64
+			total int
 		}
 		projects := make([]projectTime, 0, len(projectTotals))
 		for name, total := range projectTotals {
@@ -73,14 +98,18 @@ func generateWeeklyReport(data map[string]DayData) {
 		})
 
 		for _, pt := range projects {
-			percentage := (pt.total / totalTracked) * 100
+			percentage := float64(pt.total) / float64(totalTracked) * 100
 			bar := progressBar(percentage, 15)
-			fmt.Printf("  %s %s%.1f%%%s (%s%.0f%%%s of week) %s\n",
-				bar, ColorBlue, pt.total, ColorReset,
-				ColorCyan, percentage, ColorReset, pt.name)
+			fmt.Printf("  %s %s%.1f%%%s %s (%s%.0f%%%s of %s) %s\n",
+				bar, ColorBlue, toPercent(pt.total, config, ""), ColorReset, formatDuration(pt.total),
+				ColorCyan, percentage, ColorReset, period, pt.name)
 		}
 	}
 
+	if groupBy != "" {
+		printGroupedTotals(projectTotals, config, groupBy)
+	}
+
 	fmt.Println()
 }
 
@@ -100,18 +129,18 @@ func generateProjectReport(data map[string]DayData, projectName string, config C
 	sort.Strings(dates)
 
 	type dayEntry struct {
-		date string
-		pct  float64
+		date    string
+		minutes int
 	}
 
 	entries := make([]dayEntry, 0)
-	var totalPct float64
+	var totalMinutes int
 
 	for _, dateStr := range dates {
 		day := data[dateStr]
-		if pct, ok := day.Projects[projectName]; ok {
-			entries = append(entries, dayEntry{dateStr, pct})
-			totalPct += pct
+		if minutes, ok := day.Projects[projectName]; ok {
+			entries = append(entries, dayEntry{dateStr, minutes})
+			totalMinutes += minutes
 		}
 	}
 
@@ -122,8 +151,8 @@ func generateProjectReport(data map[string]DayData, projectName string, config C
 
 	fmt.Printf("\n%sSummary:%s\n", ColorBold, ColorReset)
 	fmt.Printf("  Days worked: %d\n", len(entries))
-	fmt.Printf("  Total time: %.1f%%\n", totalPct)
-	fmt.Printf("  Average per day: %.1f%%\n", totalPct/float64(len(entries)))
+	fmt.Printf("  Total time: %.1f%% (%s)\n", toPercent(totalMinutes, config, ""), formatDuration(totalMinutes))
+	fmt.Printf("  Average per day: %.1f%%\n", toPercent(totalMinutes, config, "")/float64(len(entries)))
 
 	// Show last 10 entries
 	fmt.Printf("\n%sRecent Activity:%s\n", ColorBold, ColorReset)
@@ -135,14 +164,14 @@ func generateProjectReport(data map[string]DayData, projectName string, config C
 	for i := len(entries) - 1; i >= start; i-- {
 		entry := entries[i]
 		t, _ := time.Parse("2006-01-02", entry.date)
-		fmt.Printf("  %s: %s%.1f%%%s\n",
-			t.Format("Jan 2, 2006"), ColorBlue, entry.pct, ColorReset)
+		fmt.Printf("  %s: %s%.1f%%%s (%s)\n",
+			t.Format("Jan 2, 2006"), ColorBlue, toPercent(entry.minutes, config, entry.date), ColorReset, formatDuration(entry.minutes))
 	}
 
 	fmt.Println()
 }
 
-func generateStatsReport(data map[string]DayData) {
+func generateStatsReport(data map[string]DayData, config Config, groupBy string) {
 	fmt.Println()
 	fmt.Printf("%s📊 Statistics%s\n", ColorBold, ColorReset)
 	fmt.Println(strings.Repeat("─", 60))
@@ -153,14 +182,22 @@ func generateStatsReport(data map[string]DayData) {
 	}
 
 	// Overall stats
-	var totalTracked float64
-	var totalAvailable float64
+	var totalTracked int
+	var totalAvailable int
 	overAllocatedDays := 0
 	fullyAllocatedDays := 0
 	projectFrequency := make(map[string]int)
+	projectTotals := make(map[string]int)
+	leaveDays := 0
 
 	for _, day := range data {
-		available := getAvailablePercent(day)
+		// Holidays and leave would only drag the averages down
+		if isNonWorking(day) {
+			leaveDays++
+			continue
+		}
+
+		available := getAvailableMinutes(day, config)
 		tracked := getTotalTracked(day)
 
 		totalAvailable += available
@@ -172,16 +209,24 @@ func generateStatsReport(data map[string]DayData) {
 			fullyAllocatedDays++
 		}
 
-		for project := range day.Projects {
+		for project, minutes := range day.Projects {
 			projectFrequency[project]++
+			projectTotals[project] += minutes
 		}
 	}
 
-	avgTracked := totalTracked / float64(len(data))
-	avgAvailable := totalAvailable / float64(len(data))
+	workedDays := len(data) - leaveDays
+	var avgTracked, avgAvailable float64
+	if workedDays > 0 {
+		avgTracked = toPercent(totalTracked, config, "") / float64(workedDays)
+		avgAvailable = toPercent(totalAvailable, config, "") / float64(workedDays)
+	}
 
 	fmt.Printf("\n%sOverall:%s\n", ColorBold, ColorReset)
-	fmt.Printf("  Total days tracked: %d\n", len(data))
+	fmt.Printf("  Total days tracked: %d\n", workedDays)
+	if leaveDays > 0 {
+		fmt.Printf("  Holiday & leave days: %d\n", leaveDays)
+	}
 	fmt.Printf("  Average tracked: %.1f%%/day\n", avgTracked)
 	fmt.Printf("  Average available: %.1f%%/day\n", avgAvailable)
 	fmt.Printf("  Fully allocated days: %d\n", fullyAllocatedDays)
@@ -210,5 +255,9 @@ func generateStatsReport(data map[string]DayData) {
 		}
 	}
 
+	if groupBy != "" {
+		printGroupedTotals(projectTotals, config, groupBy)
+	}
+
 	fmt.Println()
 }
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rrule is a parsed RRULE. Only the parts calendars use for meetings are
+// supported: FREQ (DAILY to YEARLY), INTERVAL, COUNT, UNTIL, BYDAY (with
+// ordinals such as 1MO or -1FR), BYMONTHDAY, BYMONTH and WKST.
+type rrule struct {
+	Freq       string
+	Interval   int
+	Count      int
+	Until      time.Time // Zero if the rule doesn't end by date
+	ByDay      []rruleDay
+	ByMonthDay []int
+	ByMonth    []int
+	WeekStart  time.Weekday
+}
+
+// rruleDay is a BYDAY entry: a weekday, and with N set only the Nth (or, if
+// negative, Nth from last) such weekday of the month
+type rruleDay struct {
+	Weekday time.Weekday
+	N       int
+}
+
+var icsWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// parseRRule reads an RRULE value for an event starting in loc
+func parseRRule(value string, allDay bool, loc *time.Location) (rrule, error) {
+	r := rrule{Interval: 1, WeekStart: time.Monday}
+	for _, part := range strings.Split(value, ";") {
+		key, val, _ := strings.Cut(part, "=")
+		key = strings.ToUpper(key)
+		var err error
+		switch key {
+		case "FREQ":
+			r.Freq = strings.ToUpper(val)
+			if r.Freq != "DAILY" && r.Freq != "WEEKLY" && r.Freq != "MONTHLY" && r.Freq != "YEARLY" {
+				return r, fmt.Errorf("unsupported RRULE frequency %s", val)
+			}
+		case "INTERVAL":
+			if r.Interval, err = strconv.Atoi(val); err != nil || r.Interval < 1 {
+				return r, fmt.Errorf("invalid RRULE interval %s", val)
+			}
+		case "COUNT":
+			if r.Count, err = strconv.Atoi(val); err != nil || r.Count < 1 {
+				return r, fmt.Errorf("invalid RRULE count %s", val)
+			}
+		case "UNTIL":
+			until, untilAllDay, err := parseICSTime(icalProperty{Name: "UNTIL", Value: val})
+			if err != nil {
+				return r, err
+			}
+			// A date-only UNTIL includes that whole day, in the event's zone
+			if untilAllDay && !allDay {
+				until = time.Date(until.Year(), until.Month(), until.Day()+1, 0, 0, 0, 0, loc).Add(-time.Nanosecond)
+			}
+			r.Until = until
+		case "BYDAY":
+			for _, d := range strings.Split(val, ",") {
+				d = strings.ToUpper(strings.TrimSpace(d))
+				if len(d) < 2 {
+					return r, fmt.Errorf("invalid RRULE BYDAY %s", val)
+				}
+				weekday, ok := icsWeekdays[d[len(d)-2:]]
+				if !ok {
+					return r, fmt.Errorf("invalid RRULE BYDAY %s", val)
+				}
+				n := 0
+				if ordinal := d[:len(d)-2]; ordinal != "" {
+					if n, err = strconv.Atoi(ordinal); err != nil || n == 0 {
+						return r, fmt.Errorf("invalid RRULE BYDAY %s", val)
+					}
+				}
+				r.ByDay = append(r.ByDay, rruleDay{weekday, n})
+			}
+		case "BYMONTHDAY":
+			if r.ByMonthDay, err = parseRRuleInts(val, 31); err != nil {
+				return r, err
+			}
+		case "BYMONTH":
+			if r.ByMonth, err = parseRRuleInts(val, 12); err != nil {
+				return r, err
+			}
+		case "WKST":
+			weekday, ok := icsWeekdays[strings.ToUpper(val)]
+			if !ok {
+				return r, fmt.Errorf("invalid RRULE WKST %s", val)
+			}
+			r.WeekStart = weekday
+		case "":
+		default:
+			return r, fmt.Errorf("unsupported RRULE part %s", key)
+		}
+	}
+	if r.Freq == "" {
+		return r, fmt.Errorf("RRULE has no FREQ")
+	}
+	return r, nil
+}
+
+func parseRRuleInts(val string, max int) ([]int, error) {
+	var values []int
+	for _, s := range strings.Split(val, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || n == 0 || n > max || n < -max {
+			return nil, fmt.Errorf("invalid RRULE value %s", val)
+		}
+		values = append(values, n)
+	}
+	return values, nil
+}
+
+// occurrences lists the starts, in local time, of an event's occurrences
+// that fall on the days from..to (local midnight, inclusive), expanding its
+// RRULE and leaving out its EXDATEs. The rule repeats at the same wall-clock
+// time in the event's own zone, so a meeting set in another zone moves in
+// local time when only one of the zones changes for daylight saving.
+func occurrences(event icalEvent, from, to time.Time) ([]time.Time, error) {
+	value := event.Props["RRULE"]
+	if value == "" {
+		if day := midnight(event.Start); !day.Before(from) && !day.After(to) {
+			return []time.Time{event.Start}, nil
+		}
+		return nil, nil
+	}
+	loc := event.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	r, err := parseRRule(value, event.AllDay, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	start := event.Start.In(loc)
+	var starts []time.Time
+	count := 0
+	for day := dayOf(start); ; day = day.AddDate(0, 0, 1) {
+		occurrence := time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), start.Second(), 0, loc)
+		local := occurrence.Local()
+		if midnight(local).After(to) {
+			break
+		}
+		if !r.matches(day, start) {
+			continue
+		}
+		if !r.Until.IsZero() && occurrence.After(r.Until) {
+			break
+		}
+		// COUNT includes excluded occurrences, as in RFC 5545
+		count++
+		if r.Count > 0 && count > r.Count {
+			break
+		}
+		if !midnight(local).Before(from) && !isExDate(event, occurrence) {
+			starts = append(starts, local)
+		}
+	}
+	return starts, nil
+}
+
+// midnight is the start of t's day in local time
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// dayOf is the start of t's day in t's own zone
+func dayOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// isExDate reports whether an EXDATE removes the occurrence. A date-only
+// EXDATE (read as local midnight) removes that whole day of the event's.
+func isExDate(event icalEvent, occurrence time.Time) bool {
+	for _, ex := range event.ExDates {
+		if ex.Equal(occurrence) {
+			return true
+		}
+		if ex.Equal(midnight(ex)) && dayOf(occurrence).Format("20060102") == ex.Format("20060102") {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether day (at midnight) is one the rule, starting on
+// start, recurs on. Both are in the event's zone.
+func (r rrule) matches(day, start time.Time) bool {
+	first := dayOf(start)
+	if len(r.ByMonth) > 0 && !containsInt(r.ByMonth, int(day.Month())) {
+		return false
+	}
+
+	switch r.Freq {
+	case "DAILY":
+		days := int(day.Sub(first).Hours()/24 + 0.5)
+		if days%r.Interval != 0 {
+			return false
+		}
+		return (len(r.ByDay) == 0 || r.matchesWeekday(day)) && (len(r.ByMonthDay) == 0 || matchesMonthDay(day, r.ByMonthDay))
+
+	case "WEEKLY":
+		weeks := int(r.weekOf(day).Sub(r.weekOf(first)).Hours()/(24*7) + 0.5)
+		if weeks%r.Interval != 0 {
+			return false
+		}
+		if len(r.ByDay) == 0 {
+			return day.Weekday() == start.Weekday()
+		}
+		return r.matchesWeekday(day)
+
+	case "MONTHLY":
+		months := (day.Year()-first.Year())*12 + int(day.Month()-first.Month())
+		if months%r.Interval != 0 {
+			return false
+		}
+		return r.matchesDayOfMonth(day, start)
+
+	case "YEARLY":
+		if (day.Year()-first.Year())%r.Interval != 0 {
+			return false
+		}
+		if len(r.ByMonth) == 0 && day.Month() != start.Month() {
+			return false
+		}
+		return r.matchesDayOfMonth(day, start)
+	}
+	return false
+}
+
+// matchesDayOfMonth applies BYMONTHDAY and BYDAY within a month, defaulting
+// to the start's day of the month
+func (r rrule) matchesDayOfMonth(day, start time.Time) bool {
+	if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+		return day.Day() == start.Day()
+	}
+	if len(r.ByMonthDay) > 0 && !matchesMonthDay(day, r.ByMonthDay) {
+		return false
+	}
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	last := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, day.Location()).Day()
+	for _, d := range r.ByDay {
+		if d.Weekday != day.Weekday() {
+			continue
+		}
+		switch {
+		case d.N == 0,
+			d.N > 0 && (day.Day()-1)/7+1 == d.N,
+			d.N < 0 && (last-day.Day())/7+1 == -d.N:
+			return true
+		}
+	}
+	return false
+}
+
+func (r rrule) matchesWeekday(day time.Time) bool {
+	for _, d := range r.ByDay {
+		if d.Weekday == day.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// weekOf is the first day of day's week, by WKST
+func (r rrule) weekOf(day time.Time) time.Time {
+	offset := (int(day.Weekday()) - int(r.WeekStart) + 7) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+func matchesMonthDay(day time.Time, monthDays []int) bool {
+	last := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, day.Location()).Day()
+	for _, n := range monthDays {
+		if n == day.Day() || (n < 0 && last+n+1 == day.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt(values []int, n int) bool {
+	for _, v := range values {
+		if v == n {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setLocal makes loc the local zone for the rest of the test
+func setLocal(t *testing.T, name string) {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("no zone data for %s: %v", name, err)
+	}
+	saved := time.Local
+	time.Local = loc
+	t.Cleanup(func() { time.Local = saved })
+}
+
+// icsCalendar wraps events, given as lines without the BEGIN/END, in a
+// VCALENDAR
+func icsCalendar(events ...string) string {
+	var b strings.Builder
+	b.WriteString("BEGIN:VCALENDAR\r\nVERSION:2.0\r\n")
+	for _, event := range events {
+		b.WriteString("BEGIN:VEVENT\r\n" + strings.ReplaceAll(strings.TrimSpace(event), "\n", "\r\n") + "\r\nEND:VEVENT\r\n")
+	}
+	b.WriteString("END:VCALENDAR\r\n")
+	return b.String()
+}
+
+func TestOccurrences(t *testing.T) {
+	setLocal(t, "UTC")
+	tests := []struct {
+		name     string
+		event    string
+		from, to string
+		want     []string
+	}{
+		{"first Monday", `
+DTSTART:20250106T100000
+RRULE:FREQ=MONTHLY;BYDAY=1MO`,
+			"2025-01-01", "2025-04-30",
+			[]string{"2025-01-06 10:00", "2025-02-03 10:00", "2025-03-03 10:00", "2025-04-07 10:00"}},
+		{"last Friday", `
+DTSTART:20250131T150000
+RRULE:FREQ=MONTHLY;BYDAY=-1FR`,
+			"2025-01-01", "2025-04-30",
+			[]string{"2025-01-31 15:00", "2025-02-28 15:00", "2025-03-28 15:00", "2025-04-25 15:00"}},
+		{"last day of the month", `
+DTSTART:20250131T090000
+RRULE:FREQ=MONTHLY;BYMONTHDAY=-1`,
+			"2025-01-01", "2025-04-30",
+			[]string{"2025-01-31 09:00", "2025-02-28 09:00", "2025-03-31 09:00", "2025-04-30 09:00"}},
+		{"fortnightly, weeks from Monday", `
+DTSTART:19970805T090000
+RRULE:FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=MO`,
+			"1997-08-01", "1997-09-30",
+			[]string{"1997-08-05 09:00", "1997-08-10 09:00", "1997-08-19 09:00", "1997-08-24 09:00"}},
+		{"fortnightly, weeks from Sunday", `
+DTSTART:19970805T090000
+RRULE:FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=SU`,
+			"1997-08-01", "1997-09-30",
+			[]string{"1997-08-05 09:00", "1997-08-17 09:00", "1997-08-19 09:00", "1997-08-31 09:00"}},
+		{"COUNT includes deleted occurrences", `
+DTSTART:20250301T090000
+RRULE:FREQ=DAILY;COUNT=5
+EXDATE:20250303T090000`,
+			"2025-03-01", "2025-03-31",
+			[]string{"2025-03-01 09:00", "2025-03-02 09:00", "2025-03-04 09:00", "2025-03-05 09:00"}},
+		{"date-only UNTIL includes that day", `
+DTSTART:20250303T170000
+RRULE:FREQ=DAILY;UNTIL=20250305`,
+			"2025-03-01", "2025-03-31",
+			[]string{"2025-03-03 17:00", "2025-03-04 17:00", "2025-03-05 17:00"}},
+		{"only the range", `
+DTSTART:20250303T170000
+RRULE:FREQ=DAILY;COUNT=10`,
+			"2025-03-05", "2025-03-06",
+			[]string{"2025-03-05 17:00", "2025-03-06 17:00"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := parseICS(strings.NewReader(icsCalendar("UID:test\nSUMMARY:Test" + tt.event)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			from, _ := time.ParseInLocation("2006-01-02", tt.from, time.Local)
+			to, _ := time.ParseInLocation("2006-01-02", tt.to, time.Local)
+			starts, err := occurrences(events[0], from, to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, start := range starts {
+				got = append(got, start.Format("2006-01-02 15:04"))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestOccurrencesInEventZone checks that a rule repeats at the same time in
+// the event's zone, not in local time, across daylight saving changes
+func TestOccurrencesInEventZone(t *testing.T) {
+	setLocal(t, "Europe/London")
+	if _, err := time.LoadLocation("America/New_York"); err != nil {
+		t.Skipf("no zone data for America/New_York: %v", err)
+	}
+	events, err := parseICS(strings.NewReader(icsCalendar(`
+UID:sync
+SUMMARY:Sync
+DTSTART;TZID=America/New_York:20250303T090000
+RRULE:FREQ=WEEKLY
+EXDATE;TZID=America/New_York:20250317T090000`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	from, _ := time.ParseInLocation("2006-01-02", "2025-03-01", time.Local)
+	to, _ := time.ParseInLocation("2006-01-02", "2025-04-01", time.Local)
+	starts, err := occurrences(events[0], from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, start := range starts {
+		got = append(got, start.Format("2006-01-02 15:04"))
+	}
+	// New York moves to summer time on 9 March, London on 30 March
+	want := []string{"2025-03-03 14:00", "2025-03-10 13:00", "2025-03-24 13:00", "2025-03-31 14:00"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestImportICS checks that moved occurrences are booked where they moved
+// to, that events past midnight count on both days, and that events no rule
+// matches are excluded under their titles
+func TestImportICS(t *testing.T) {
+	setLocal(t, "UTC")
+	filename := filepath.Join(t.TempDir(), "work.ics")
+	calendar := icsCalendar(`
+UID:standup
+SUMMARY:Standup
+DTSTART:20251201T093000
+DTEND:20251201T094500
+RRULE:FREQ=DAILY;COUNT=3`, `
+UID:standup
+SUMMARY:Standup
+RECURRENCE-ID:20251202T093000
+DTSTART:20251202T140000
+DTEND:20251202T143000`, `
+UID:release
+SUMMARY:Release
+DTSTART:20251203T230000
+DTEND:20251204T013000`, `
+UID:acme
+SUMMARY:Acme call
+DTSTART:20251204T100000
+DTEND:20251204T110000`)
+	if err := os.WriteFile(filename, []byte(calendar), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := Config{
+		DayHours:      8,
+		Projects:      []string{"Acme"},
+		CalendarRules: []CalendarRule{{Pattern: "acme", Project: "Acme"}},
+	}
+	store := openTestStore(t)
+	r := dayRange{From: "2025-12-01", To: "2025-12-04", Label: "test"}
+	if err := importICS(filename, r, config, store, false); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]map[string]int{
+		"2025-12-01": {"Standup": 15},
+		"2025-12-02": {"Standup": 30},
+		"2025-12-03": {"Standup": 15, "Release": 60},
+		"2025-12-04": {"Release": 90},
+	}
+	for date, meetings := range want {
+		day, _, err := store.LoadDay(date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(day.ExcludedMeetings, meetings) {
+			t.Errorf("%s: excluded %v, want %v", date, day.ExcludedMeetings, meetings)
+		}
+	}
+	day, _, err := store.LoadDay("2025-12-04")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if day.Projects["Acme"] != 60 {
+		t.Errorf("2025-12-04: Acme %d minutes, want 60", day.Projects["Acme"])
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var scheduleDays = []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
+
+// handleSchedule implements "config schedule": with no arguments it shows the
+// schedule, otherwise it sets hours for weekdays or a specific date
+func handleSchedule(config Config, args []string) error {
+	if len(args) == 0 {
+		printSchedule(config)
+		return nil
+	}
+
+	if args[0] == "clear" {
+		if len(args) < 2 {
+			fmt.Println("Usage: timetrack config schedule clear <days|date>")
+			return nil
+		}
+		if date, err := parseDate(args[1]); err == nil {
+			if _, ok := config.ScheduleOverrides[date]; !ok {
+				fmt.Printf("No override for %s\n", date)
+				return nil
+			}
+			delete(config.ScheduleOverrides, date)
+		} else {
+			days, err := parseScheduleDays(args[1])
+			if err != nil {
+				return err
+			}
+			for _, d := range days {
+				delete(config.Schedule, d)
+			}
+		}
+		if err := saveConfig(config); err != nil {
+			return err
+		}
+		fmt.Printf("Cleared schedule for %s\n", args[1])
+		return nil
+	}
+
+	if len(args) < 2 {
+		fmt.Println("Usage: timetrack config schedule <days|date> <hours>")
+		return nil
+	}
+	hours, err := strconv.ParseFloat(args[1], 64)
+	if err != nil || hours < 0 || hours > 24 {
+		return fmt.Errorf("invalid hours: %s", args[1])
+	}
+
+	if date, err := parseDate(args[0]); err == nil {
+		if config.ScheduleOverrides == nil {
+			config.ScheduleOverrides = make(map[string]float64)
+		}
+		config.ScheduleOverrides[date] = hours
+		if err := saveConfig(config); err != nil {
+			return err
+		}
+		fmt.Printf("Working time on %s set to %s\n", date, formatDuration(hoursToMinutes(hours)))
+		return nil
+	}
+
+	days, err := parseScheduleDays(args[0])
+	if err != nil {
+		return err
+	}
+	if config.Schedule == nil {
+		config.Schedule = make(map[string]float64)
+	}
+	for _, d := range days {
+		config.Schedule[d] = hours
+	}
+	if err := saveConfig(config); err != nil {
+		return err
+	}
+	fmt.Printf("Working time on %s set to %s\n", strings.Join(days, ", "), formatDuration(hoursToMinutes(hours)))
+	return nil
+}
+
+// parseScheduleDays expands a comma-separated list of days, "weekdays" or
+// "weekends" into weekday keys
+func parseScheduleDays(spec string) ([]string, error) {
+	var days []string
+	for _, part := range strings.Split(strings.ToLower(spec), ",") {
+		part = strings.TrimSpace(part)
+		switch part {
+		case "weekdays":
+			days = append(days, scheduleDays[:5]...)
+		case "weekends":
+			days = append(days, scheduleDays[5:]...)
+		case "daily":
+			days = append(days, scheduleDays...)
+		default:
+			if len(part) >= 3 {
+				part = part[:3]
+			}
+			valid := false
+			for _, d := range scheduleDays {
+				if d == part {
+					valid = true
+					break
+				}
+			}
+			if !valid {
+				return nil, fmt.Errorf("unknown day %q (use mon-sun, weekdays, weekends, daily or a date)", part)
+			}
+			days = append(days, part)
+		}
+	}
+	return days, nil
+}
+
+func printSchedule(config Config) {
+	fmt.Println("Working schedule:")
+	for _, d := range scheduleDays {
+		source := ""
+		if _, ok := config.Schedule[d]; !ok {
+			source = " (day_hours)"
+		}
+		fmt.Printf("   • %s: %s%s\n", d, formatDuration(scheduleMinutes(config, d)), source)
+	}
+
+	if len(config.ScheduleOverrides) > 0 {
+		fmt.Println("\nDate overrides:")
+		for _, date := range sortedKeys(config.ScheduleOverrides) {
+			label := date
+			if t, err := time.Parse("2006-01-02", date); err == nil {
+				label = t.Format("Mon 02/01/06")
+			}
+			fmt.Printf("   • %s: %s\n", label, formatDuration(hoursToMinutes(config.ScheduleOverrides[date])))
+		}
+	}
+}
+
+// scheduleMinutes is the working time for a weekday key under the weekly
+// schedule, ignoring date overrides
+func scheduleMinutes(config Config, weekday string) int {
+	if hours, ok := config.Schedule[weekday]; ok {
+		return hoursToMinutes(hours)
+	}
+	return getDayMinutes(config, "")
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// searchOptions are the filters for 'timetrack search'
+type searchOptions struct {
+	Query      string
+	From, To   string // Inclusive dates; empty means open-ended
+	Project    string // Only entries whose project contains this
+	MinMinutes int    // Only entries at least this long
+}
+
+// searchMatch is one entry that matched on a day
+type searchMatch struct {
+	Kind    string `json:"kind"` // "project" or "meeting"
+	Name    string `json:"name"`
+	Minutes int    `json:"minutes"`
+	Note    string `json:"note,omitempty"`
+}
+
+// parseSearchArgs reads the query and filters. The dates to search take
+// the same range forms as show and export; without one, all history is
+// searched.
+func parseSearchArgs(args []string, config Config) (searchOptions, error) {
+	var opts searchOptions
+	r, args, err := parseRangeArgs(args)
+	if err != nil {
+		return opts, err
+	}
+	if r != nil {
+		opts.From, opts.To = r.From, r.To
+	}
+
+	var terms []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch arg {
+		case "--project", "-p", "--min":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("%s flag requires a value", arg)
+			}
+			value := args[i+1]
+			i++
+			switch arg {
+			case "--project", "-p":
+				opts.Project = resolveProject(value, config)
+			case "--min":
+				hours, err := strconv.ParseFloat(value, 64)
+				if err != nil || hours < 0 {
+					return opts, fmt.Errorf("invalid --min hours: %s", value)
+				}
+				opts.MinMinutes = hoursToMinutes(hours)
+			}
+		default:
+			terms = append(terms, arg)
+		}
+	}
+	opts.Query = strings.Join(terms, " ")
+	if opts.Query == "" && opts.Project == "" && opts.MinMinutes == 0 {
+		return opts, fmt.Errorf("nothing to search for")
+	}
+	return opts, nil
+}
+
+// searchDay returns the entries on day that match, checking project names,
+// notes and excluded meeting names
+func searchDay(day DayData, opts searchOptions) []searchMatch {
+	query := strings.ToLower(opts.Query)
+	project := strings.ToLower(opts.Project)
+	var matches []searchMatch
+
+	for _, name := range sortedKeys(day.Projects) {
+		minutes := day.Projects[name]
+		note := day.Notes[name]
+		if project != "" && !strings.Contains(strings.ToLower(name), project) {
+			continue
+		}
+		if minutes < opts.MinMinutes {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(name), query) &&
+			!strings.Contains(strings.ToLower(note), query) {
+			continue
+		}
+		matches = append(matches, searchMatch{Kind: "project", Name: name, Minutes: minutes, Note: note})
+	}
+
+	// Meetings aren't projects, so a project filter rules them out
+	if project == "" {
+		for _, name := range sortedKeys(day.ExcludedMeetings) {
+			minutes := day.ExcludedMeetings[name]
+			if minutes < opts.MinMinutes {
+				continue
+			}
+			if query != "" && !strings.Contains(strings.ToLower(name), query) {
+				continue
+			}
+			matches = append(matches, searchMatch{Kind: "meeting", Name: name, Minutes: minutes})
+		}
+	}
+	return matches
+}
+
+// searchResult is a day with entries that matched
+type searchResult struct {
+	Date           string        `json:"date"`
+	MatchedMinutes int           `json:"matched_minutes"` // Project time only
+	TrackedMinutes int           `json:"tracked_minutes"`
+	Matches        []searchMatch `json:"matches"`
+}
+
+// findMatches returns the days with matching entries, newest first
+func findMatches(store Store, opts searchOptions) ([]searchResult, error) {
+	data, err := store.Range(opts.From, opts.To)
+	if err != nil {
+		return nil, err
+	}
+
+	dates := make([]string, 0, len(data))
+	for date := range data {
+		dates = append(dates, date)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(dates)))
+
+	results := []searchResult{}
+	for _, date := range dates {
+		day := data[date]
+		matches := searchDay(day, opts)
+		if len(matches) == 0 {
+			continue
+		}
+		dayTotal := 0
+		for _, m := range matches {
+			if m.Kind == "project" {
+				dayTotal += m.Minutes
+			}
+		}
+		results = append(results, searchResult{date, dayTotal, getTotalTracked(day), matches})
+	}
+	return results, nil
+}
+
+// runSearch prints every day with matching entries, newest first, with the
+// matching time per day and overall
+func runSearch(store Store, opts searchOptions) error {
+	results, err := findMatches(store, opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Printf("%s🔍 Search: %s%s\n", ColorBold, describeSearch(opts), ColorReset)
+	fmt.Println(strings.Repeat("─", 60))
+
+	var total, days int
+	latest := ""
+	for _, r := range results {
+		days++
+		if latest == "" {
+			latest = r.Date
+		}
+		total += r.MatchedMinutes
+
+		fmt.Printf("\n%s%s%s  %s matched of %s tracked\n", ColorBold, r.Date, ColorReset,
+			formatDuration(r.MatchedMinutes), formatDuration(r.TrackedMinutes))
+		for _, m := range r.Matches {
+			label := m.Name
+			if m.Kind == "meeting" {
+				label += ColorGray + " (excluded meeting)" + ColorReset
+			}
+			fmt.Printf("   • %s%6s%s  %s\n", ColorBlue, formatDuration(m.Minutes), ColorReset, label)
+			if m.Note != "" {
+				fmt.Printf("             %s%s%s\n", ColorGray, m.Note, ColorReset)
+			}
+		}
+	}
+
+	fmt.Println()
+	if days == 0 {
+		fmt.Println("No matches found")
+		fmt.Println()
+		return nil
+	}
+	fmt.Printf("%d day(s) matched, %s of project time", days, formatDuration(total))
+	fmt.Printf(" (most recent: %s)\n\n", latest)
+	return nil
+}
+
+func describeSearch(opts searchOptions) string {
+	var parts []string
+	if opts.Query != "" {
+		parts = append(parts, fmt.Sprintf("%q", opts.Query))
+	}
+	if opts.Project != "" {
+		parts = append(parts, "project "+opts.Project)
+	}
+	if opts.MinMinutes > 0 {
+		parts = append(parts, "at least "+formatDuration(opts.MinMinutes))
+	}
+	switch {
+	case opts.From != "" && opts.To != "":
+		parts = append(parts, opts.From+" to "+opts.To)
+	case opts.From != "":
+		parts = append(parts, "since "+opts.From)
+	case opts.To != "":
+		parts = append(parts, "until "+opts.To)
+	}
+	return strings.Join(parts, ", ")
+}
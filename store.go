@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Store is the persistence backend for tracked days. Dates are always
+// YYYY-MM-DD strings, so lexical order is chronological order.
+type Store interface {
+	// LoadDay returns the stored day and whether it exists.
+	LoadDay(date string) (DayData, bool, error)
+	SaveDay(day DayData) error
+	SaveDays(days []DayData) error
+	DeleteDay(date string) error
+	// ReplaceAll swaps the entire history for days, e.g. when restoring a backup.
+	ReplaceAll(days []DayData) error
+	// Range returns all days between from and to inclusive. An empty bound
+	// is open-ended, so Range("", "") returns everything.
+	Range(from, to string) (map[string]DayData, error)
+	// Dates lists every stored date in ascending order.
+	Dates() ([]string, error)
+	// Projects lists every project with tracked time, alphabetically.
+	Projects() ([]string, error)
+	Close() error
+}
+
+const (
+	StorageJSON   = "json"
+	StorageSQLite = "sqlite"
+)
+
+func openStore(config Config) (Store, error) {
+	switch storageBackend(config) {
+	case StorageJSON:
+		return newJSONStore(getDataPath(), newBackupPolicy(config))
+	case StorageSQLite:
+		return newSQLiteStore(getDatabasePath(), newBackupPolicy(config))
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q (use %s or %s)", config.Storage, StorageJSON, StorageSQLite)
+	}
+}
+
+// openStoreReadOnly opens the store for reading without the data lock: it
+// writes nothing, so no snapshots are taken, older files are upgraded in
+// memory only, and a store that doesn't exist yet isn't created
+func openStoreReadOnly(config Config) (Store, error) {
+	path := storagePath(config)
+	if !fileExists(path) {
+		return nil, fmt.Errorf("no data at %s", path)
+	}
+	switch storageBackend(config) {
+	case StorageJSON:
+		return newJSONStore(path, nil)
+	case StorageSQLite:
+		return openSQLiteReadOnly(path)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q (use %s or %s)", config.Storage, StorageJSON, StorageSQLite)
+	}
+}
+
+// storagePath is the file holding tracked days for the configured backend
+func storagePath(config Config) string {
+	if storageBackend(config) == StorageSQLite {
+		return getDatabasePath()
+	}
+	return getDataPath()
+}
+
+func storageBackend(config Config) string {
+	if config.Storage == "" {
+		return StorageJSON
+	}
+	return strings.ToLower(config.Storage)
+}
+
+// loadRecentDays returns the most recent n days that have data
+func loadRecentDays(store Store, n int) (map[string]DayData, error) {
+	dates, err := store.Dates()
+	if err != nil {
+		return nil, err
+	}
+	// Leave booked ahead of time isn't recent activity
+	end := len(dates)
+	for end > 0 && dates[end-1] > today() {
+		end--
+	}
+	dates = dates[:end]
+	if len(dates) == 0 || n <= 0 {
+		return map[string]DayData{}, nil
+	}
+	if len(dates) > n {
+		dates = dates[len(dates)-n:]
+	}
+	return store.Range(dates[0], dates[len(dates)-1])
+}
+
+// migrateStorage copies every day from the current backend into the target
+// backend, replacing whatever the target held from an earlier switch. The
+// source is left untouched so switching back is lossless.
+func migrateStorage(config Config, target string) error {
+	target = strings.ToLower(target)
+	if target != StorageJSON && target != StorageSQLite {
+		return fmt.Errorf("unknown storage backend %q (use %s or %s)", target, StorageJSON, StorageSQLite)
+	}
+
+	source, err := openStore(config)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	days, err := source.Range("", "")
+	if err != nil {
+		return err
+	}
+
+	targetConfig := config
+	targetConfig.Storage = target
+	dest, err := openStore(targetConfig)
+	if err != nil {
+		return err
+	}
+	defer dest.Close()
+
+	dates := make([]string, 0, len(days))
+	for date := range days {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	batch := make([]DayData, 0, len(dates))
+	for _, date := range dates {
+		batch = append(batch, days[date])
+	}
+	if err := dest.ReplaceAll(batch); err != nil {
+		return err
+	}
+
+	fmt.Printf("Copied %d days from %s to %s storage\n", len(batch), storageBackend(config), target)
+	return nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import "sort"
+
+// jsonStore keeps the whole history in a single JSON file. Every write
+// rewrites the file, which is simple but slow for long histories.
+type jsonStore struct {
+	path    string
+	data    map[string]DayData
+	backups *backupPolicy
+	pending migrationReport // Upgrade applied in memory at load time
+}
+
+// newJSONStore opens the JSON file at path. backups may be nil to disable
+// snapshots, e.g. when reading a backup file itself.
+func newJSONStore(path string, backups *backupPolicy) (*jsonStore, error) {
+	data, report, err := readDataFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &jsonStore{path: path, data: data, backups: backups, pending: report}, nil
+}
+
+// migrate writes the upgraded file back if it was loaded from an older schema
+func (s *jsonStore) migrate(dryRun bool) (migrationReport, error) {
+	if dryRun || !s.pending.needed() {
+		return s.pending, nil
+	}
+	return s.pending, s.write()
+}
+
+func (s *jsonStore) LoadDay(date string) (DayData, bool, error) {
+	day, ok := s.data[date]
+	if !ok {
+		return DayData{}, false, nil
+	}
+	return cloneDay(day), true, nil
+}
+
+func (s *jsonStore) SaveDay(day DayData) error {
+	return s.SaveDays([]DayData{day})
+}
+
+func (s *jsonStore) SaveDays(days []DayData) error {
+	for _, day := range days {
+		s.data[day.Date] = cloneDay(day)
+	}
+	return s.write()
+}
+
+func (s *jsonStore) DeleteDay(date string) error {
+	if _, ok := s.data[date]; !ok {
+		return nil
+	}
+	delete(s.data, date)
+	return s.write()
+}
+
+func (s *jsonStore) ReplaceAll(days []DayData) error {
+	s.data = make(map[string]DayData, len(days))
+	for _, day := range days {
+		s.data[day.Date] = cloneDay(day)
+	}
+	return s.write()
+}
+
+// write snapshots the file as it was before this command, then saves
+func (s *jsonStore) write() error {
+	err := s.backups.snapshot(".json", func(backupPath string) error {
+		if !fileExists(s.path) {
+			return nil
+		}
+		return copyFile(s.path, backupPath)
+	})
+	if err != nil {
+		return err
+	}
+	return saveData(s.path, s.data)
+}
+
+func (s *jsonStore) Range(from, to string) (map[string]DayData, error) {
+	result := make(map[string]DayData)
+	for date, day := range s.data {
+		if (from == "" || date >= from) && (to == "" || date <= to) {
+			result[date] = cloneDay(day)
+		}
+	}
+	return result, nil
+}
+
+func (s *jsonStore) Dates() ([]string, error) {
+	dates := make([]string, 0, len(s.data))
+	for date := range s.data {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+	return dates, nil
+}
+
+func (s *jsonStore) Projects() ([]string, error) {
+	return getAllProjects(s.data), nil
+}
+
+func (s *jsonStore) Close() error {
+	return nil
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteStore keeps one row per day, with the day itself stored as JSON so
+// the table does not need to change whenever DayData grows a field.
+type sqliteStore struct {
+	db      *sql.DB
+	backups *backupPolicy
+	version int // Schema version of the stored day JSON (PRAGMA user_version)
+}
+
+// newSQLiteStore opens (creating if needed) the database at path and upgrades
+// its rows to the current schema. backups may be nil to disable snapshots;
+// such stores are left untouched and upgraded in memory instead.
+func newSQLiteStore(path string, backups *backupPolicy) (*sqliteStore, error) {
+	s, err := openSQLite(path, backups)
+	if err != nil {
+		return nil, err
+	}
+	if backups != nil {
+		if _, err := s.migrate(false); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// openSQLite opens the database without running migrations
+func openSQLite(path string, backups *backupPolicy) (*sqliteStore, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS days (
+		date TEXT PRIMARY KEY,
+		data TEXT NOT NULL
+	)`)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialise database: %w", err)
+	}
+
+	s := &sqliteStore{db: db, backups: backups}
+	if err := s.db.QueryRow(`PRAGMA user_version`).Scan(&s.version); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if s.version == 0 {
+		// Either a brand new database or one written before versioning
+		var count int
+		if err := s.db.QueryRow(`SELECT COUNT(*) FROM days`).Scan(&count); err != nil {
+			db.Close()
+			return nil, err
+		}
+		if count > 0 {
+			s.version = 1
+		} else if err := s.setVersion(s.db, dataSchemaVersion); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	if err := checkSchemaVersion(path, s.version, dataSchemaVersion); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// openSQLiteReadOnly opens the database read-only (mode=ro), for reading
+// without the data lock. The schema isn't set up, so reads fail on a
+// database no command has written to yet, and older rows are upgraded in
+// memory only.
+func openSQLiteReadOnly(path string) (*sqliteStore, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	s := &sqliteStore{db: db}
+	if err := s.db.QueryRow(`PRAGMA user_version`).Scan(&s.version); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if s.version == 0 {
+		// Written before versioning, or empty
+		s.version = 1
+	}
+	if err := checkSchemaVersion(path, s.version, dataSchemaVersion); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+func (s *sqliteStore) setVersion(db execer, version int) error {
+	// PRAGMA values cannot be bound as parameters
+	if _, err := db.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, version)); err != nil {
+		return fmt.Errorf("failed to set schema version: %w", err)
+	}
+	s.version = version
+	return nil
+}
+
+// migrate rewrites every row at the current schema version. With dryRun it
+// only reports what would change.
+func (s *sqliteStore) migrate(dryRun bool) (migrationReport, error) {
+	report := newMigrationReport(s.version, dayMigrations, dataSchemaVersion)
+	if !report.needed() {
+		return report, nil
+	}
+
+	rows, err := s.db.Query(`SELECT date, data FROM days ORDER BY date`)
+	if err != nil {
+		return report, fmt.Errorf("failed to query days: %w", err)
+	}
+	var days []DayData
+	for rows.Next() {
+		var date, raw string
+		if err := rows.Scan(&date, &raw); err != nil {
+			rows.Close()
+			return report, err
+		}
+		day, err := decodeDay([]byte(raw), s.version, &report)
+		if err != nil {
+			rows.Close()
+			return report, fmt.Errorf("failed to decode %s: %w", date, err)
+		}
+		days = append(days, day)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return report, err
+	}
+
+	if dryRun {
+		return report, nil
+	}
+	if err := s.write(false, days); err != nil {
+		return report, err
+	}
+	return report, s.setVersion(s.db, dataSchemaVersion)
+}
+
+func (s *sqliteStore) LoadDay(date string) (DayData, bool, error) {
+	var raw string
+	err := s.db.QueryRow(`SELECT data FROM days WHERE date = ?`, date).Scan(&raw)
+	if err == sql.ErrNoRows {
+		return DayData{}, false, nil
+	}
+	if err != nil {
+		return DayData{}, false, fmt.Errorf("failed to load %s: %w", date, err)
+	}
+
+	day, err := decodeDay([]byte(raw), s.version, nil)
+	if err != nil {
+		return DayData{}, false, fmt.Errorf("failed to decode %s: %w", date, err)
+	}
+	return day, true, nil
+}
+
+func (s *sqliteStore) SaveDay(day DayData) error {
+	return s.SaveDays([]DayData{day})
+}
+
+func (s *sqliteStore) SaveDays(days []DayData) error {
+	return s.write(false, days)
+}
+
+func (s *sqliteStore) ReplaceAll(days []DayData) error {
+	return s.write(true, days)
+}
+
+// write upserts days in one transaction, optionally clearing the table first
+func (s *sqliteStore) write(replace bool, days []DayData) error {
+	if err := s.backup(); err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if replace {
+		if _, err := tx.Exec(`DELETE FROM days`); err != nil {
+			return fmt.Errorf("failed to clear days: %w", err)
+		}
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO days (date, data) VALUES (?, ?)
+		ON CONFLICT(date) DO UPDATE SET data = excluded.data`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare save: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, day := range days {
+		bytes, err := json.Marshal(day)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", day.Date, err)
+		}
+		if _, err := stmt.Exec(day.Date, string(bytes)); err != nil {
+			return fmt.Errorf("failed to save %s: %w", day.Date, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	return nil
+}
+
+func (s *sqliteStore) DeleteDay(date string) error {
+	if err := s.backup(); err != nil {
+		return err
+	}
+	if _, err := s.db.Exec(`DELETE FROM days WHERE date = ?`, date); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", date, err)
+	}
+	return nil
+}
+
+func (s *sqliteStore) Range(from, to string) (map[string]DayData, error) {
+	rows, err := s.db.Query(`SELECT date, data FROM days
+		WHERE (? = '' OR date >= ?) AND (? = '' OR date <= ?)
+		ORDER BY date`, from, from, to, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query days: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]DayData)
+	for rows.Next() {
+		var date, raw string
+		if err := rows.Scan(&date, &raw); err != nil {
+			return nil, err
+		}
+		day, err := decodeDay([]byte(raw), s.version, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", date, err)
+		}
+		result[date] = day
+	}
+	return result, rows.Err()
+}
+
+func (s *sqliteStore) Dates() ([]string, error) {
+	return s.queryStrings(`SELECT date FROM days ORDER BY date`)
+}
+
+func (s *sqliteStore) Projects() ([]string, error) {
+	return s.queryStrings(`SELECT DISTINCT p.key FROM days, json_each(days.data, '$.projects') AS p ORDER BY p.key`)
+}
+
+func (s *sqliteStore) queryStrings(query string) ([]string, error) {
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var result []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+	}
+	return result, rows.Err()
+}
+
+// backup snapshots the database as it was before this command's first write
+func (s *sqliteStore) backup() error {
+	return s.backups.snapshot(".db", func(backupPath string) error {
+		_, err := s.db.Exec(`VACUUM INTO ?`, backupPath)
+		return err
+	})
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestStorageSwitchBack checks that switching back to a backend used before
+// replaces its old days rather than bringing deleted ones back
+func TestStorageSwitchBack(t *testing.T) {
+	dir := t.TempDir()
+	t.Cleanup(func() { dataDirOverride = "" })
+	run := func(args ...string) {
+		t.Helper()
+		argv := append([]string{"--data-dir", dir, "--quiet"}, args...)
+		if code := runCommand(argv); code != 0 {
+			t.Fatalf("%v exited with %d", args, code)
+		}
+	}
+	tracked := func(storage string) bool {
+		t.Helper()
+		store, err := openStore(Config{Storage: storage})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer store.Close()
+		_, ok, err := store.LoadDay("2025-12-01")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ok
+	}
+
+	run("add", "alpha", "2", "--date", "2025-12-01")
+	run("storage", "sqlite")
+	run("storage", "json")
+	// clear asks first
+	answer := filepath.Join(dir, "answer")
+	if err := os.WriteFile(answer, []byte("y\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stdin, err := os.Open(answer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+	saved := os.Stdin
+	os.Stdin = stdin
+	t.Cleanup(func() { os.Stdin = saved })
+	run("clear", "--date", "2025-12-01")
+	if tracked(StorageJSON) {
+		t.Fatal("clear left 2025-12-01")
+	}
+	run("storage", "sqlite")
+	if tracked(StorageSQLite) {
+		t.Fatal("2025-12-01 came back after switching to sqlite again")
+	}
+
+	run("add", "beta", "1", "--date", "2025-12-02")
+	run("storage", "json")
+	if tracked(StorageJSON) {
+		t.Fatal("2025-12-01 came back after switching to json again")
+	}
+}
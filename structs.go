@@ -1,23 +1,71 @@
 package main
 
+const defaultDayHours = 8
+
+// DayData holds one day's tracked time. All durations are whole minutes;
+// percentages are derived from the day's scheduled length when displayed.
 type DayData struct {
-	Date             string             `json:"date"`
-	ExcludedPercent  float64            `json:"excluded_percent"`
-	Projects         map[string]float64 `json:"projects"`
-	ExcludedMeetings map[string]float64 `json:"excluded_meetings"`
-	LastModified     string             `json:"last_modified,omitempty"` // Track last added/edited project for undo
+	Date             string            `json:"date"`
+	Projects         map[string]int    `json:"projects"`
+	ExcludedMeetings map[string]int    `json:"excluded_meetings"`
+	Leave            string            `json:"leave,omitempty"` // Set on non-working days: "holiday", "annual", "sick", ...
+	Notes            map[string]string `json:"notes,omitempty"` // Project → what the time was spent on
+
+	// Intervals holds start/end times for project time entered as ranges or
+	// with the timer. They can cover less than the project's total (time
+	// entered as hours has none) but never more.
+	Intervals map[string][]Interval `json:"intervals,omitempty"`
 }
 
 type RecurringMeeting struct {
 	Name    string   `json:"name"`
-	Percent float64  `json:"percent"`
+	Minutes int      `json:"minutes"`
 	Days    []string `json:"days"` // "mon", "tue", "wed", "thu", "fri", "sat", "sun", or "daily", "weekdays"
 }
 
+// ProjectMeta describes a project for grouping in reports and exports
+type ProjectMeta struct {
+	Category string   `json:"category,omitempty"`
+	Client   string   `json:"client,omitempty"`
+	Billable bool     `json:"billable,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// TimesheetTemplate lays CSV exports out like the spreadsheet they're pasted
+// into. Unset fields keep the default export format.
+type TimesheetTemplate struct {
+	Columns      []string `json:"columns,omitempty"`       // Project columns in sheet order (default: Config.Projects)
+	DateFormat   string   `json:"date_format,omitempty"`   // e.g. "DD/MM/YYYY", "D-MMM" (default "YYYY-MM-DD")
+	ValueFormat  string   `json:"value_format,omitempty"`  // "percent" (default), "hours" or "decimal" (fraction of the day)
+	EmptyCell    string   `json:"empty_cell,omitempty"`    // "blank" (default) or "0"
+	TotalFormula string   `json:"total_formula,omitempty"` // "sum", or a formula with {row}, e.g. "=SUM(B{row}:F{row})"
+}
+
+// CalendarRule says what import ics does with calendar events whose title
+// matches Pattern. The first matching rule wins.
+type CalendarRule struct {
+	Pattern string `json:"pattern"`           // Case-insensitive, matched anywhere in the title; * matches any text
+	Project string `json:"project,omitempty"` // Book the time to this project...
+	Meeting string `json:"meeting,omitempty"` // ...else exclude it under this name (default: the event title)
+	Skip    bool   `json:"skip,omitempty"`    // Don't import these events
+}
+
 type Config struct {
-	ReminderTimes     []string           `json:"reminder_times"`
-	RecurringMeetings []RecurringMeeting `json:"recurring_meetings"`
-	Projects          []string           `json:"projects"`
-	Aliases           map[string]string  `json:"aliases"`
-	TimesheetURL      string             `json:"timesheet_url,omitempty"`
+	SchemaVersion     int                    `json:"schema_version"`
+	ReminderTimes     []string               `json:"reminder_times"`
+	RecurringMeetings []RecurringMeeting     `json:"recurring_meetings"`
+	Projects          []string               `json:"projects"`
+	Aliases           map[string]string      `json:"aliases"`
+	ProjectInfo       map[string]ProjectMeta `json:"project_info,omitempty"`    // Category, client, billable and tags by project
+	Archived          []string               `json:"archived,omitempty"`        // Projects hidden from suggestions and weekly exports
+	StrictProjects    bool                   `json:"strict_projects,omitempty"` // Reject unknown project names unless --new is given
+	TimesheetURL      string                 `json:"timesheet_url,omitempty"`
+	CSVColumns        []string               `json:"csv_columns,omitempty"`        // CSV export column order, e.g. ["date", "projects", "total", "notes"]
+	Timesheet         *TimesheetTemplate     `json:"timesheet,omitempty"`          // Layout of CSV exports for the corporate timesheet
+	CalendarRules     []CalendarRule         `json:"calendar_rules,omitempty"`     // How import ics maps events to meetings and projects
+	Storage           string                 `json:"storage,omitempty"`            // "json" (default) or "sqlite"
+	BackupRetention   int                    `json:"backup_retention,omitempty"`   // Snapshots to keep (default 20, -1 disables)
+	DayHours          float64                `json:"day_hours,omitempty"`          // Length of a working day (default 8)
+	Schedule          map[string]float64     `json:"schedule,omitempty"`           // Hours per weekday ("mon".."sun"); unset days use day_hours
+	ScheduleOverrides map[string]float64     `json:"schedule_overrides,omitempty"` // Hours on specific dates (YYYY-MM-DD)
 }
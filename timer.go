@@ -0,0 +1,294 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Timer is the running live timer, kept in timer.json so it survives between
+// commands
+type Timer struct {
+	Project  string      `json:"project"`
+	Started  time.Time   `json:"started,omitzero"`   // Start of the current run; zero (and left out) while paused
+	Segments []TimerSpan `json:"segments,omitempty"` // Runs finished by a pause
+}
+
+type TimerSpan struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+func (t Timer) paused() bool {
+	return t.Started.IsZero()
+}
+
+// spans lists every period the timer has been running, up to now
+func (t Timer) spans(now time.Time) []TimerSpan {
+	spans := append([]TimerSpan(nil), t.Segments...)
+	if !t.paused() {
+		spans = append(spans, TimerSpan{Start: t.Started, End: now})
+	}
+	return spans
+}
+
+func (t Timer) elapsed() time.Duration {
+	var total time.Duration
+	for _, span := range t.spans(time.Now()) {
+		total += span.End.Sub(span.Start)
+	}
+	return total
+}
+
+func (t Timer) firstStart() time.Time {
+	if len(t.Segments) > 0 {
+		return t.Segments[0].Start
+	}
+	return t.Started
+}
+
+// intervalsByDate turns the running time into time ranges to the nearest
+// minute, split at midnight so a timer left on overnight is recorded against
+// each day it ran on
+func (t Timer) intervalsByDate(now time.Time) map[string][]Interval {
+	byDate := make(map[string][]Interval)
+	for _, span := range t.spans(now) {
+		start := span.Start.Local()
+		end := span.End.Local()
+		for start.Before(end) {
+			midnight := time.Date(start.Year(), start.Month(), start.Day()+1, 0, 0, 0, 0, time.Local)
+			pieceEnd := end
+			if midnight.Before(pieceEnd) {
+				pieceEnd = midnight
+			}
+			dayStart := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.Local)
+			from := int(start.Sub(dayStart).Round(time.Minute).Minutes())
+			to := int(pieceEnd.Sub(dayStart).Round(time.Minute).Minutes())
+			if to > from {
+				date := start.Format("2006-01-02")
+				byDate[date] = append(byDate[date], Interval{Start: formatClock(from), End: formatClock(to)})
+			}
+			start = pieceEnd
+		}
+	}
+	return byDate
+}
+
+// loadTimer returns the running timer, or nil if none is running
+func loadTimer() (*Timer, error) {
+	bytes, err := os.ReadFile(getTimerPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read timer: %w", err)
+	}
+	var timer Timer
+	if err := json.Unmarshal(bytes, &timer); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", getTimerPath(), err)
+	}
+	return &timer, nil
+}
+
+func saveTimer(timer Timer) error {
+	bytes, err := json.MarshalIndent(timer, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode timer: %w", err)
+	}
+	return writeFileAtomic(getTimerPath(), bytes, 0644)
+}
+
+func clearTimer() error {
+	if err := os.Remove(getTimerPath()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to clear timer: %w", err)
+	}
+	return nil
+}
+
+func startTimer(project string) error {
+	running, err := loadTimer()
+	if err != nil {
+		return err
+	}
+	if running != nil {
+		return fmt.Errorf("timer already running for %s (%s); use 'timetrack switch <project>' or 'timetrack stop-timer'",
+			running.Project, formatDuration(int(running.elapsed().Minutes())))
+	}
+	if err := saveTimer(Timer{Project: project, Started: time.Now()}); err != nil {
+		return err
+	}
+	fmt.Printf("⏱️  Timer started for %s at %s\n", project, time.Now().Format("15:04"))
+	return nil
+}
+
+func pauseTimer() error {
+	running, err := loadTimer()
+	if err != nil {
+		return err
+	}
+	if running == nil {
+		return fmt.Errorf("no timer running")
+	}
+	if running.paused() {
+		fmt.Printf("Timer for %s is already paused\n", running.Project)
+		return nil
+	}
+	running.Segments = append(running.Segments, TimerSpan{Start: running.Started, End: time.Now()})
+	running.Started = time.Time{}
+	if err := saveTimer(*running); err != nil {
+		return err
+	}
+	fmt.Printf("⏸️  Paused %s at %s (%s so far)\n", running.Project, time.Now().Format("15:04"),
+		formatDuration(int(running.elapsed().Minutes())))
+	return nil
+}
+
+func resumeTimer() error {
+	running, err := loadTimer()
+	if err != nil {
+		return err
+	}
+	if running == nil {
+		return fmt.Errorf("no timer to resume; use 'timetrack start-timer <project>'")
+	}
+	if !running.paused() {
+		fmt.Printf("Timer for %s is already running\n", running.Project)
+		return nil
+	}
+	running.Started = time.Now()
+	if err := saveTimer(*running); err != nil {
+		return err
+	}
+	fmt.Printf("▶️  Resumed %s at %s\n", running.Project, time.Now().Format("15:04"))
+	return nil
+}
+
+// stopTimer adds the timer's running time to its project on each day it ran,
+// the same way 'add' records time. Days where it ran longer than the working
+// day are capped, or the user is asked what to keep. It returns the timer that
+// was stopped (nil if none was running) and the days that were updated.
+func stopTimer(store Store, config Config) (*Timer, []DayData, error) {
+	running, err := loadTimer()
+	if err != nil || running == nil {
+		return running, nil, err
+	}
+
+	byDate := running.intervalsByDate(time.Now())
+	var days []DayData
+	for _, date := range sortedKeys(byDate) {
+		intervals := byDate[date]
+		ran := 0
+		for _, iv := range intervals {
+			ran += iv.minutes()
+		}
+		minutes := limitTimerMinutes(running.Project, date, ran, config)
+		if minutes <= 0 {
+			continue
+		}
+
+		// Keep the start and end times unless they were trimmed or clash
+		// with time already recorded
+		existing, err := getDateData(store, config, date)
+		if err != nil {
+			return nil, nil, err
+		}
+		var day DayData
+		if minutes == ran && len(findOverlaps(existing, running.Project, intervals)) == 0 {
+			day, err = addProjectIntervals(store, config, date, running.Project, intervals, "")
+		} else {
+			day, err = addProjectMinutes(store, config, date, running.Project, minutes)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		fmt.Printf("⏹️  Stopped %s: added %s", running.Project, formatDuration(minutes))
+		if date != today() {
+			fmt.Printf(" on %s", date)
+		}
+		fmt.Println()
+		warnOverAllocated(day, config)
+		days = append(days, day)
+	}
+	if len(days) == 0 {
+		fmt.Printf("⏹️  Stopped %s; nothing recorded\n", running.Project)
+	}
+
+	if err := clearTimer(); err != nil {
+		return nil, nil, err
+	}
+	return running, days, nil
+}
+
+// limitTimerMinutes guards against a forgotten timer: when it ran longer than
+// the working day, ask how much to keep, or cap it if nobody can answer
+func limitTimerMinutes(project, date string, minutes int, config Config) int {
+	limit := percentBase(config, date)
+	if minutes <= limit {
+		return minutes
+	}
+
+	fmt.Printf("⚠️  The %s timer ran %s on %s, longer than the %s working day.\n",
+		project, formatDuration(minutes), date, formatDuration(limit))
+	if !stdinIsTerminal() {
+		fmt.Printf("   Recorded %s; use 'timetrack edit' if that's wrong\n", formatDuration(limit))
+		return limit
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Printf("Keep [a]ll %s, [c]ap at %s, or enter hours to record: ", formatDuration(minutes), formatDuration(limit))
+		input, err := reader.ReadString('\n')
+		input = strings.ToLower(strings.TrimSpace(input))
+		switch {
+		case input == "a":
+			return minutes
+		case input == "c" || input == "" || err != nil:
+			return limit
+		}
+		if hours, err := strconv.ParseFloat(input, 64); err == nil && hours >= 0 {
+			return hoursToMinutes(hours)
+		}
+		fmt.Println("Invalid choice")
+	}
+}
+
+func printTimer(config Config) error {
+	running, err := loadTimer()
+	if err != nil {
+		return err
+	}
+	if running == nil {
+		fmt.Println("No timer running")
+		return nil
+	}
+
+	elapsed := int(running.elapsed().Minutes())
+	since := running.firstStart()
+	started := since.Format("15:04")
+	if since.Format("2006-01-02") != today() {
+		started = since.Format("02/01/2006 15:04")
+	}
+	state := "running"
+	if running.paused() {
+		state = "paused"
+	}
+	fmt.Printf("⏱️  %s%s%s %s, started %s (%s)\n", ColorBold, running.Project, ColorReset,
+		state, started, formatDuration(elapsed))
+
+	if limit := percentBase(config, today()); elapsed > limit {
+		fmt.Printf("%s⚠️  That's longer than the %s working day. Forgot to stop it?%s\n",
+			ColorYellow, formatDuration(limit), ColorReset)
+	}
+	return nil
+}
+
+// stdinIsTerminal reports whether someone is at the keyboard to answer a prompt
+func stdinIsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Value formats for TimesheetTemplate.ValueFormat
+const (
+	valuePercent = "percent"
+	valueHours   = "hours"
+	valueDecimal = "decimal"
+)
+
+var valueFormats = []string{valuePercent, valueHours, valueDecimal}
+
+// sheetFormat is how CSV cells are written and read back: the timesheet
+// template's settings, or the defaults when there is no template
+type sheetFormat struct {
+	dateLayout string // Go time layout
+	hasYear    bool
+	values     string
+	zero       bool   // Write "0" rather than leaving empty cells blank
+	total      string // "" (the value), "sum" or a formula with {row}
+}
+
+func newSheetFormat(config Config) (sheetFormat, error) {
+	f := sheetFormat{dateLayout: csvDateFormat, hasYear: true, values: valuePercent}
+	t := config.Timesheet
+	if t == nil {
+		return f, nil
+	}
+	if t.DateFormat != "" {
+		layout, err := parseDateFormat(t.DateFormat)
+		if err != nil {
+			return f, err
+		}
+		f.dateLayout = layout
+		f.hasYear = strings.Contains(layout, "06")
+	}
+	if t.ValueFormat != "" {
+		if !containsName(valueFormats, t.ValueFormat) {
+			return f, fmt.Errorf("unknown timesheet value format %q (use %s)", t.ValueFormat, strings.Join(valueFormats, ", "))
+		}
+		f.values = strings.ToLower(t.ValueFormat)
+	}
+	f.zero = t.EmptyCell == "0"
+	f.total = t.TotalFormula
+	return f, nil
+}
+
+// parseDateFormat turns a spreadsheet date format (dd/mm/yyyy, d-mmm,
+// ddd dd/mm/yy, ...) into a Go time layout. As in Excel, "ddd" is the
+// weekday and "mmm" the month name.
+func parseDateFormat(format string) (string, error) {
+	tokens := map[rune][]string{
+		'd': {"2", "02", "Mon", "Monday"},
+		'm': {"1", "01", "Jan", "January"},
+		'y': {"", "06", "", "2006"},
+	}
+	var layout strings.Builder
+	hasDay, hasMonth := false, false
+	runes := []rune(strings.ToLower(format))
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		forms, ok := tokens[r]
+		if !ok {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return "", fmt.Errorf("invalid date format %q: use d, m and y (e.g. dd/mm/yyyy)", format)
+			}
+			layout.WriteRune(r)
+			i++
+			continue
+		}
+		n := 1
+		for i+n < len(runes) && runes[i+n] == r {
+			n++
+		}
+		if n > 4 || forms[n-1] == "" {
+			return "", fmt.Errorf("invalid date format %q: %q", format, string(runes[i:i+n]))
+		}
+		switch {
+		case r == 'd' && n <= 2:
+			hasDay = true
+		case r == 'm':
+			hasMonth = true
+		}
+		layout.WriteString(forms[n-1])
+		i += n
+	}
+	if !hasDay || !hasMonth {
+		return "", fmt.Errorf("invalid date format %q: needs a day and a month", format)
+	}
+	return layout.String(), nil
+}
+
+func (f sheetFormat) formatDate(date string) string {
+	return mustParseDay(date).Format(f.dateLayout)
+}
+
+// parseDate reads a date cell; a layout without a year means this year
+func (f sheetFormat) parseDate(cell string) (string, bool) {
+	t, err := time.Parse(f.dateLayout, cell)
+	if err != nil {
+		return "", false
+	}
+	if !f.hasYear {
+		t = time.Date(time.Now().Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	}
+	return t.Format("2006-01-02"), true
+}
+
+// formatValue writes minutes in the sheet's value format, with as few
+// decimals as import needs to get the same minutes back
+func (f sheetFormat) formatValue(minutes int, config Config, date string) string {
+	if minutes == 0 {
+		if f.zero {
+			return "0"
+		}
+		return ""
+	}
+	base := float64(percentBase(config, date))
+	switch f.values {
+	case valueHours:
+		return minimalDecimals(float64(minutes)/60, 0, minutes, func(v float64) float64 { return v * 60 })
+	case valueDecimal:
+		return minimalDecimals(float64(minutes)/base, 2, minutes, func(v float64) float64 { return v * base })
+	default:
+		return minimalDecimals(toPercent(minutes, config, date), 1, minutes, func(v float64) float64 { return v / 100 * base }) + "%"
+	}
+}
+
+// parseValue reads a value cell back into minutes. A trailing % always
+// means a percentage; other numbers are in the sheet's value format.
+func (f sheetFormat) parseValue(cell string, config Config, date string) (int, error) {
+	base := float64(percentBase(config, date))
+	values := f.values
+	if strings.HasSuffix(cell, "%") {
+		values = valuePercent
+		cell = strings.TrimSuffix(cell, "%")
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(cell), 64)
+	if err != nil {
+		return 0, err
+	}
+	switch values {
+	case valueHours:
+		v *= 60
+	case valueDecimal:
+		v *= base
+	default:
+		v = v / 100 * base
+	}
+	return int(math.Round(v)), nil
+}
+
+// minimalDecimals formats v with the fewest decimals (at least min) that
+// still round back to minutes
+func minimalDecimals(v float64, min, minutes int, toMinutes func(float64) float64) string {
+	for decimals := min; decimals < 6; decimals++ {
+		cell := strconv.FormatFloat(v, 'f', decimals, 64)
+		if back, _ := strconv.ParseFloat(cell, 64); int(math.Round(toMinutes(back))) == minutes {
+			return cell
+		}
+	}
+	return strconv.FormatFloat(v, 'f', 6, 64)
+}
+
+// totalFormula is the total cell for a sheet row (1-based, after the header)
+// when the template asks for a formula. cols are the 0-based indexes of the
+// project columns, leaving out subtotals.
+func (f sheetFormat) totalFormula(row int, cols []int) string {
+	if f.total != "sum" {
+		return strings.ReplaceAll(f.total, "{row}", strconv.Itoa(row))
+	}
+	if len(cols) == 0 {
+		return ""
+	}
+	if cols[len(cols)-1]-cols[0] == len(cols)-1 {
+		return fmt.Sprintf("=SUM(%s%d:%s%d)", columnLetter(cols[0]), row, columnLetter(cols[len(cols)-1]), row)
+	}
+	cells := make([]string, len(cols))
+	for i, c := range cols {
+		cells[i] = fmt.Sprintf("%s%d", columnLetter(c), row)
+	}
+	return "=SUM(" + strings.Join(cells, ",") + ")"
+}
+
+// columnLetter is a spreadsheet column name: 0 is A, 26 is AA
+func columnLetter(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// timesheetColumns is the project column order of the timesheet: the
+// template's columns, else Config.Projects (from projects parse or set)
+func timesheetColumns(config Config) []string {
+	if config.Timesheet != nil && len(config.Timesheet.Columns) > 0 {
+		return config.Timesheet.Columns
+	}
+	return config.Projects
+}
+
+// orderProjects lists every project in order, even those without time so
+// the layout matches the sheet, then the rest of found alphabetically (also
+// returned as extra)
+func orderProjects(order, found []string) (projects, extra []string) {
+	projects = append(projects, order...)
+	for _, p := range found {
+		if !containsName(projects, p) {
+			extra = append(extra, p)
+		}
+	}
+	sort.Strings(extra)
+	return append(projects, extra...), extra
+}
+
+// sheetProjects orders found for a CSV export: in timesheet column order
+// when there's a template, else as found
+func sheetProjects(found []string, config Config) (projects, extra []string) {
+	if config.Timesheet == nil {
+		return found, nil
+	}
+	return orderProjects(timesheetColumns(config), found)
+}
+
+// handleTimesheet shows or changes the timesheet template
+func handleTimesheet(config Config, args []string) error {
+	if len(args) == 0 {
+		printTimesheet(config)
+		return nil
+	}
+	if args[0] == "off" {
+		config.Timesheet = nil
+		if err := saveConfig(config); err != nil {
+			return err
+		}
+		fmt.Println("Timesheet template removed; exports use the default format")
+		return nil
+	}
+	if len(args) < 2 {
+		printTimesheetUsage()
+		return nil
+	}
+
+	t := TimesheetTemplate{}
+	if config.Timesheet != nil {
+		t = *config.Timesheet
+	}
+	value := strings.Join(args[1:], " ")
+	switch args[0] {
+	case "columns":
+		if value == "projects" {
+			t.Columns = nil
+			break
+		}
+		t.Columns = nil
+		for _, p := range strings.Split(value, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				t.Columns = append(t.Columns, p)
+			}
+		}
+	case "date-format":
+		if _, err := parseDateFormat(value); err != nil {
+			return err
+		}
+		t.DateFormat = value
+	case "values":
+		value = strings.ToLower(value)
+		if !containsName(valueFormats, value) {
+			return fmt.Errorf("unknown value format %q (use %s)", value, strings.Join(valueFormats, ", "))
+		}
+		t.ValueFormat = value
+	case "empty":
+		if value != "blank" && value != "0" {
+			return fmt.Errorf("empty cells are \"blank\" or \"0\"")
+		}
+		t.EmptyCell = value
+	case "total":
+		if value == "value" {
+			value = ""
+		}
+		t.TotalFormula = value
+	default:
+		printTimesheetUsage()
+		return nil
+	}
+
+	config.Timesheet = &t
+	if err := saveConfig(config); err != nil {
+		return err
+	}
+	printTimesheet(config)
+	return nil
+}
+
+func printTimesheetUsage() {
+	fmt.Println("Usage: timetrack config timesheet columns <P1,P2,...>|projects")
+	fmt.Println("       timetrack config timesheet date-format <dd/mm/yyyy, d-mmm, ...>")
+	fmt.Println("       timetrack config timesheet values <percent|hours|decimal>")
+	fmt.Println("       timetrack config timesheet empty <blank|0>")
+	fmt.Println("       timetrack config timesheet total <value|sum|formula with {row}>")
+	fmt.Println("       timetrack config timesheet off")
+}
+
+func printTimesheet(config Config) {
+	t := config.Timesheet
+	if t == nil {
+		fmt.Println("No timesheet template: exports use alphabetical projects, ISO dates and percentages")
+		return
+	}
+	columns := strings.Join(t.Columns, ", ")
+	if columns == "" {
+		columns = "config projects"
+		if len(config.Projects) > 0 {
+			columns += " (" + strings.Join(config.Projects, ", ") + ")"
+		}
+	}
+	orDefault := func(s, def string) string {
+		if s == "" {
+			return def
+		}
+		return s
+	}
+	fmt.Println("Timesheet template:")
+	fmt.Printf("   Columns:      %s\n", columns)
+	fmt.Printf("   Date format:  %s\n", orDefault(t.DateFormat, "yyyy-mm-dd"))
+	fmt.Printf("   Values:       %s\n", orDefault(t.ValueFormat, valuePercent))
+	fmt.Printf("   Empty cells:  %s\n", orDefault(t.EmptyCell, "blank"))
+	fmt.Printf("   Total:        %s\n", orDefault(t.TotalFormula, "value"))
+}
@@ -0,0 +1,556 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// XLSX workbooks are written and read with archive/zip and encoding/xml: just
+// the parts Excel, LibreOffice and Google Sheets need, with no dependencies.
+
+const (
+	xlsxMainNS = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+	xlsxRelNS  = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	xlsxPkgNS  = "http://schemas.openxmlformats.org/package/2006/relationships"
+)
+
+// Cell styles, indexes into cellXfs in xlsxStyles
+const (
+	xlsxStyleDefault = iota
+	xlsxStyleHeader
+	xlsxStyleDate
+	xlsxStylePercent
+	xlsxStyleNumber
+)
+
+// Sheet periods for --sheets
+const (
+	sheetsByWeek  = "week"
+	sheetsByMonth = "month"
+)
+
+// parseSheetsFlag reads --sheets week|month, for how an XLSX export is split
+// into sheets (default week)
+func parseSheetsFlag(args []string) (string, []string, error) {
+	per := sheetsByWeek
+	remainingArgs := []string{}
+	for i := 0; i < len(args); i++ {
+		if args[i] != "--sheets" {
+			remainingArgs = append(remainingArgs, args[i])
+			continue
+		}
+		if i+1 >= len(args) {
+			return "", nil, fmt.Errorf("--sheets flag requires week or month")
+		}
+		per = strings.ToLower(args[i+1])
+		i++
+		if per != sheetsByWeek && per != sheetsByMonth {
+			return "", nil, fmt.Errorf("unknown --sheets %q (use week or month)", per)
+		}
+	}
+	return per, remainingArgs, nil
+}
+
+// xlsxCell is a cell to write. Exactly one of the value fields is used.
+type xlsxCell struct {
+	Text    string
+	Number  float64
+	Formula string // Without the leading "=", with Number as its cached value
+	IsNum   bool
+	Style   int
+}
+
+type xlsxSheet struct {
+	Name string
+	Rows [][]xlsxCell
+}
+
+// exportToXLSX writes the days in r to a workbook with a sheet per week or
+// month (per). Columns follow the timesheet (Config.Projects order), values
+// are numbers formatted as percentages of the day and the total is a SUM
+// formula.
+func exportToXLSX(data map[string]DayData, config Config, r dayRange, filename, groupBy, per string) error {
+	format, err := newSheetFormat(config)
+	if err != nil {
+		return err
+	}
+	dates := r.dates(data)
+	if len(dates) == 0 {
+		return fmt.Errorf("no days to export")
+	}
+
+	projects := getRangeProjects(data, config, dates)
+	if order := timesheetColumns(config); len(order) > 0 {
+		var extra []string
+		projects, extra = orderProjects(order, projects)
+		if len(extra) > 0 {
+			fmt.Printf("Note: %s not in the timesheet columns; added as extra columns at the end\n", strings.Join(extra, ", "))
+		}
+	}
+	columns := csvColumns(projects, config, groupBy)
+	layout := csvLayout(config, true)
+
+	// Split the days into sheets
+	var sheets []xlsxSheet
+	var sheetDates [][]string
+	for _, date := range dates {
+		year, week := mustParseDay(date).ISOWeek()
+		name := fmt.Sprintf("%d-W%02d", year, week)
+		if per == sheetsByMonth {
+			name = mustParseDay(date).Format("January 2006")
+		}
+		if len(sheets) == 0 || sheets[len(sheets)-1].Name != name {
+			sheets = append(sheets, xlsxSheet{Name: name})
+			sheetDates = append(sheetDates, nil)
+		}
+		sheetDates[len(sheetDates)-1] = append(sheetDates[len(sheetDates)-1], date)
+	}
+
+	valueStyle := xlsxStylePercent
+	if format.values != valuePercent {
+		valueStyle = xlsxStyleNumber
+	}
+	value := func(minutes int, date string) xlsxCell {
+		if minutes == 0 && !format.zero {
+			return xlsxCell{}
+		}
+		v := float64(minutes) / float64(percentBase(config, date))
+		if format.values == valueHours {
+			v = float64(minutes) / 60
+		}
+		return xlsxCell{Number: v, IsNum: true, Style: valueStyle}
+	}
+
+	for i := range sheets {
+		var header []xlsxCell
+		var projectCols []int
+		for _, kind := range layout {
+			switch kind {
+			case csvDate:
+				header = append(header, xlsxCell{Text: "Date", Style: xlsxStyleHeader})
+			case csvProjects:
+				for _, c := range columns {
+					if !strings.HasPrefix(c.Header, subtotalPrefix) {
+						projectCols = append(projectCols, len(header))
+					}
+					header = append(header, xlsxCell{Text: c.Header, Style: xlsxStyleHeader})
+				}
+			case csvTotal:
+				header = append(header, xlsxCell{Text: "Total Time Spent", Style: xlsxStyleHeader})
+			case csvNotes:
+				header = append(header, xlsxCell{Text: "Notes", Style: xlsxStyleHeader})
+			}
+		}
+		rows := [][]xlsxCell{header}
+
+		for j, date := range sheetDates[i] {
+			day := data[date]
+			var row []xlsxCell
+			for _, kind := range layout {
+				switch kind {
+				case csvDate:
+					row = append(row, xlsxCell{Number: excelSerial(date), IsNum: true, Style: xlsxStyleDate})
+				case csvProjects:
+					for _, c := range columns {
+						row = append(row, value(c.minutes(day), date))
+					}
+				case csvTotal:
+					total := 0
+					for _, c := range columns {
+						if !strings.HasPrefix(c.Header, subtotalPrefix) {
+							total += c.minutes(day)
+						}
+					}
+					cell := value(total, date)
+					cell.IsNum, cell.Style = true, valueStyle
+					formula := format
+					if formula.total == "" {
+						formula.total = "sum"
+					}
+					cell.Formula = strings.TrimPrefix(formula.totalFormula(j+2, projectCols), "=")
+					row = append(row, cell)
+				case csvNotes:
+					row = append(row, xlsxCell{Text: formatDayNotes(day)})
+				}
+			}
+			rows = append(rows, row)
+		}
+		sheets[i].Rows = rows
+	}
+
+	dateFormat := "yyyy-mm-dd"
+	if config.Timesheet != nil && config.Timesheet.DateFormat != "" {
+		dateFormat = config.Timesheet.DateFormat
+	}
+	if err := writeXLSX(filename, sheets, dateFormat); err != nil {
+		return err
+	}
+
+	noun := "sheets"
+	if len(sheets) == 1 {
+		noun = "sheet"
+	}
+	fmt.Printf("Exported %s to %s (%d %s)\n", r.Label, filename, len(sheets), noun)
+	return nil
+}
+
+// excelSerial is a date as Excel stores it: days since 30 December 1899
+func excelSerial(date string) float64 {
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	return math.Round(mustParseDay(date).Sub(epoch).Hours() / 24)
+}
+
+func excelDate(serial float64) string {
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	return epoch.AddDate(0, 0, int(math.Floor(serial))).Format("2006-01-02")
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func writeXLSX(filename string, sheets []xlsxSheet, dateFormat string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	z := zip.NewWriter(file)
+	add := func(name, content string) error {
+		w, err := z.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+"\n"+content)
+		return err
+	}
+
+	var types, workbook, rels strings.Builder
+	types.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	workbook.WriteString(`<workbook xmlns="` + xlsxMainNS + `" xmlns:r="` + xlsxRelNS + `"><sheets>`)
+	rels.WriteString(`<Relationships xmlns="` + xlsxPkgNS + `">`)
+	for i, s := range sheets {
+		n := i + 1
+		fmt.Fprintf(&types, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+		fmt.Fprintf(&workbook, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(xlsxSheetName(s.Name)), n, n)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="%s/worksheet" Target="worksheets/sheet%d.xml"/>`, n, xlsxRelNS, n)
+	}
+	types.WriteString(`</Types>`)
+	workbook.WriteString(`</sheets></workbook>`)
+	fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="%s/styles" Target="styles.xml"/></Relationships>`, len(sheets)+1, xlsxRelNS)
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", types.String()},
+		{"_rels/.rels", `<Relationships xmlns="` + xlsxPkgNS + `">` +
+			`<Relationship Id="rId1" Type="` + xlsxRelNS + `/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+		{"xl/workbook.xml", workbook.String()},
+		{"xl/_rels/workbook.xml.rels", rels.String()},
+		{"xl/styles.xml", xlsxStyles(dateFormat)},
+	}
+	for i, s := range sheets {
+		parts = append(parts, struct{ name, content string }{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), xlsxSheetXML(s)})
+	}
+	for _, p := range parts {
+		if err := add(p.name, p.content); err != nil {
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+	}
+	if err := z.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// xlsxSheetName makes a valid sheet name: at most 31 characters, none of []:*?/\
+func xlsxSheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '-'
+		}
+		return r
+	}, name)
+	if len(name) > 31 {
+		name = name[:31]
+	}
+	return name
+}
+
+func xlsxStyles(dateFormat string) string {
+	return `<styleSheet xmlns="` + xlsxMainNS + `">` +
+		`<numFmts count="3"><numFmt numFmtId="164" formatCode="0.0%"/>` +
+		`<numFmt numFmtId="165" formatCode="` + xmlEscape(dateFormat) + `"/>` +
+		`<numFmt numFmtId="166" formatCode="0.00"/></numFmts>` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="5">` +
+		`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+		`<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+		`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+		`<xf numFmtId="166" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+		`</cellXfs>` +
+		`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+		`</styleSheet>`
+}
+
+func xlsxSheetXML(s xlsxSheet) string {
+	var b strings.Builder
+	b.WriteString(`<worksheet xmlns="` + xlsxMainNS + `">`)
+	// Keep the header row in view
+	b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	if len(s.Rows) > 0 {
+		fmt.Fprintf(&b, `<cols><col min="1" max="%d" width="16" customWidth="1"/></cols>`, len(s.Rows[0]))
+	}
+	b.WriteString(`<sheetData>`)
+	for i, row := range s.Rows {
+		fmt.Fprintf(&b, `<row r="%d">`, i+1)
+		for j, c := range row {
+			ref := fmt.Sprintf("%s%d", columnLetter(j), i+1)
+			switch {
+			case c.Formula != "":
+				fmt.Fprintf(&b, `<c r="%s" s="%d"><f>%s</f><v>%s</v></c>`, ref, c.Style, xmlEscape(c.Formula), strconv.FormatFloat(c.Number, 'f', -1, 64))
+			case c.IsNum:
+				fmt.Fprintf(&b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, c.Style, strconv.FormatFloat(c.Number, 'f', -1, 64))
+			case c.Text != "":
+				fmt.Fprintf(&b, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, c.Style, xmlEscape(c.Text))
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+// XML shapes read back from a workbook
+type (
+	xlsxWorkbookXML struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	xlsxRelsXML struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	xlsxRichText struct {
+		Text string `xml:"t"`
+		Runs []struct {
+			Text string `xml:"t"`
+		} `xml:"r"`
+	}
+	xlsxSharedStringsXML struct {
+		Items []xlsxRichText `xml:"si"`
+	}
+	xlsxStylesXML struct {
+		NumFmts []struct {
+			ID   int    `xml:"numFmtId,attr"`
+			Code string `xml:"formatCode,attr"`
+		} `xml:"numFmts>numFmt"`
+		CellXfs []struct {
+			NumFmtID int `xml:"numFmtId,attr"`
+		} `xml:"cellXfs>xf"`
+	}
+	xlsxWorksheetXML struct {
+		Rows []struct {
+			Cells []struct {
+				Ref    string       `xml:"r,attr"`
+				Type   string       `xml:"t,attr"`
+				Style  int          `xml:"s,attr"`
+				Value  string       `xml:"v"`
+				Inline xlsxRichText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+)
+
+func (t xlsxRichText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var b strings.Builder
+	for _, r := range t.Runs {
+		b.WriteString(r.Text)
+	}
+	return b.String()
+}
+
+// readXLSX reads every sheet of a workbook as rows of text, the way a CSV
+// file reads: percentage cells become "25%" and date cells "2006-01-02"
+func readXLSX(filename string) ([]sheet, error) {
+	z, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open workbook: %w", err)
+	}
+	defer z.Close()
+
+	files := make(map[string]*zip.File)
+	for _, f := range z.File {
+		files[f.Name] = f
+	}
+	decode := func(name string, v any) error {
+		f, ok := files[name]
+		if !ok {
+			return os.ErrNotExist
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		if err := xml.NewDecoder(rc).Decode(v); err != nil {
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		return nil
+	}
+
+	var workbook xlsxWorkbookXML
+	if err := decode("xl/workbook.xml", &workbook); err != nil {
+		return nil, fmt.Errorf("not an XLSX workbook: %w", err)
+	}
+	var rels xlsxRelsXML
+	if err := decode("xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, fmt.Errorf("not an XLSX workbook: %w", err)
+	}
+	targets := make(map[string]string)
+	for _, r := range rels.Relationships {
+		if strings.HasPrefix(r.Target, "/") {
+			targets[r.ID] = strings.TrimPrefix(r.Target, "/")
+		} else {
+			targets[r.ID] = path.Join("xl", r.Target)
+		}
+	}
+
+	// Both are optional
+	var shared xlsxSharedStringsXML
+	decode("xl/sharedStrings.xml", &shared)
+	var styles xlsxStylesXML
+	decode("xl/styles.xml", &styles)
+	codes := make(map[int]string)
+	for _, f := range styles.NumFmts {
+		codes[f.ID] = f.Code
+	}
+
+	// cellText renders a numeric cell by its number format
+	cellText := func(value string, style int) string {
+		if style < 0 || style >= len(styles.CellXfs) {
+			return value
+		}
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return value
+		}
+		id := styles.CellXfs[style].NumFmtID
+		switch kind := numFmtKind(id, codes[id]); kind {
+		case "percent":
+			return strconv.FormatFloat(v*100, 'f', -1, 64) + "%"
+		case "date":
+			return excelDate(v)
+		}
+		return value
+	}
+
+	var sheets []sheet
+	for _, s := range workbook.Sheets {
+		var ws xlsxWorksheetXML
+		if err := decode(targets[s.ID], &ws); err != nil {
+			return nil, fmt.Errorf("failed to read sheet %q: %w", s.Name, err)
+		}
+		var rows [][]string
+		for _, row := range ws.Rows {
+			var cells []string
+			for _, c := range row.Cells {
+				col := len(cells)
+				if c.Ref != "" {
+					col = columnIndex(c.Ref)
+				}
+				for len(cells) <= col {
+					cells = append(cells, "")
+				}
+				switch c.Type {
+				case "s":
+					if i, err := strconv.Atoi(c.Value); err == nil && i < len(shared.Items) {
+						cells[col] = shared.Items[i].String()
+					}
+				case "inlineStr":
+					cells[col] = c.Inline.String()
+				case "str", "b", "e":
+					cells[col] = c.Value
+				default:
+					cells[col] = cellText(c.Value, c.Style)
+				}
+			}
+			rows = append(rows, cells)
+		}
+		sheets = append(sheets, sheet{Name: s.Name, Rows: rows})
+	}
+	return sheets, nil
+}
+
+// numFmtKind says whether a number format shows a percentage, a date or
+// anything else (""). Built-in formats 9 and 10 are percentages and 14 to 22
+// dates; custom formats are judged by their code.
+func numFmtKind(id int, code string) string {
+	switch {
+	case id == 9 || id == 10:
+		return "percent"
+	case id >= 14 && id <= 22:
+		return "date"
+	case code == "":
+		return ""
+	}
+	// Ignore quoted text and [colour] or [locale] sections
+	var plain strings.Builder
+	quoted, bracket := false, false
+	for _, r := range strings.ToLower(code) {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case r == '[':
+			bracket = true
+		case r == ']':
+			bracket = false
+		case !bracket:
+			plain.WriteRune(r)
+		}
+	}
+	switch p := plain.String(); {
+	case strings.Contains(p, "%"):
+		return "percent"
+	case strings.ContainsAny(p, "dy") || strings.Contains(p, "mmm"):
+		return "date"
+	}
+	return ""
+}
+
+// columnIndex is the 0-based column of a cell reference such as "AB12"
+func columnIndex(ref string) int {
+	col := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+	}
+	return col - 1
+}